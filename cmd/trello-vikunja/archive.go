@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+
+	"wingaru.me/trello-migrate/internal/config"
+	"wingaru.me/trello-migrate/internal/migration"
+	"wingaru.me/trello-migrate/pkg/trello2vikunja"
+	"wingaru.me/trello-migrate/pkg/trellosource"
+)
+
+// writeArchive converts the chosen boards into a zip for the Vikunja export
+// import instead of uploading them. The projects are created by the import,
+// so neither the Vikunja data nor an api token are needed.
+func writeArchive(cfg *config.Config) error {
+	migration.Logger = logger
+	startProgress(trelloRateLimit)
+	defer bars.Wait()
+	migration.HTTPClient = bars.HTTPClient()
+
+	options, err := configure(cfg)
+	if err != nil {
+		return err
+	}
+
+	source, err := openTrelloSource(cfg)
+	if err != nil {
+		return err
+	}
+
+	trelloData, err := trellosource.Load(source)
+	if err != nil {
+		return err
+	}
+
+	projects := trello2vikunja.NewProjects(trelloData)
+	options.Boards, err = chooseBoards(projects, cfg.Migrate.Boards)
+	if err != nil {
+		return err
+	}
+
+	plan, err := convertBoards(nil, source, trelloData, projects, options)
+	if err != nil {
+		return err
+	}
+	for _, name := range plan.MissingBoards {
+		logger.Warnf("Board %s is not in the Trello export", name)
+	}
+
+	file, err := os.Create(cfg.Files.OutputArchive)
+	if err != nil {
+		return err
+	}
+
+	err = plan.WriteArchive(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Wrote %d projects to %s, import it in Vikunja under Settings > Import from other services > Vikunja", len(plan.Projects), cfg.Files.OutputArchive)
+	return nil
+}
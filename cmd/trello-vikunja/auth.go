@@ -0,0 +1,192 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/warrenwingaru/go-trello"
+	"wingaru.me/trello-migrate/internal/config"
+	"wingaru.me/trello-migrate/pkg/trellosource"
+)
+
+// authTimeout is how long "auth trello" waits for the user to authorize.
+const authTimeout = 5 * time.Minute
+
+// authPage is served at the return url. Trello puts the token into the
+// fragment, which only the browser sees, so the page posts it back.
+const authPage = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>trello-vikunja</title></head>
+<body>
+<p id="message">Sending the token to trello-vikunja...</p>
+<script>
+const fragment = new URLSearchParams(location.hash.slice(1));
+const body = new URLSearchParams({
+	state: new URLSearchParams(location.search).get("state") || "",
+	token: fragment.get("token") || "",
+});
+fetch("/token", {method: "POST", body: body})
+	.then(response => response.text())
+	.then(text => document.getElementById("message").textContent = text);
+</script>
+</body>
+</html>
+`
+
+// runAuthTrello lets the user authorize the api key in the browser, catches
+// the token on a local callback server and stores both in the config file
+// given with -config, or else in the .env file.
+func runAuthTrello(cfg *config.Config) error {
+	if cfg.Trello.APIKey == "" {
+		return fmt.Errorf("set -trello-key to the api key of your Trello Power-Up, from https://trello.com/power-ups/admin")
+	}
+	if !validTokenExpiration(cfg.Trello.TokenExpiration) {
+		return fmt.Errorf("unknown token expiration %q, use one of %v", cfg.Trello.TokenExpiration, trellosource.TokenExpirations)
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", cfg.Trello.CallbackPort))
+	if err != nil {
+		return fmt.Errorf("starting the callback server, choose another -callback-port: %w", err)
+	}
+
+	state, err := randomState()
+	if err != nil {
+		return err
+	}
+	tokens := make(chan string, 1)
+	server := &http.Server{Handler: authHandler(state, tokens)}
+	go server.Serve(listener)
+	defer server.Close()
+
+	returnURL := fmt.Sprintf("http://localhost:%d/callback?state=%s", cfg.Trello.CallbackPort, state)
+	authorizeURL := trellosource.AuthorizeURL(cfg.Trello.APIKey, "trello-vikunja", cfg.Trello.TokenExpiration, returnURL)
+	fmt.Printf("Authorize trello-vikunja to read your boards on this page, if it doesn't open by itself:\n\n  %s\n\n", authorizeURL)
+	if err := openBrowser(authorizeURL); err != nil {
+		logger.WithError(err).Debug("Could not open the browser")
+	}
+
+	var token string
+	select {
+	case token = <-tokens:
+	case <-time.After(authTimeout):
+		return fmt.Errorf("got no token from Trello within %s; if Trello showed an error about the return url, allow http://localhost:%d as origin of the api key", authTimeout, cfg.Trello.CallbackPort)
+	}
+
+	checked, err := trellosource.CheckToken(trello.NewClient(cfg.Trello.APIKey, token))
+	if err != nil {
+		return err
+	}
+
+	stored, err := storeTrelloCredentials(cfg, token)
+	if err != nil {
+		return err
+	}
+
+	expires := "never expires"
+	if checked.DateExpires != nil {
+		expires = "expires at " + checked.DateExpires.Format(time.RFC1123)
+	}
+	logger.Infof("Stored the Trello token in %s, it %s", stored, expires)
+
+	return nil
+}
+
+// storeTrelloCredentials writes the api key and token to the config file,
+// or to the .env file when there is none, and returns where they went.
+func storeTrelloCredentials(cfg *config.Config, token string) (string, error) {
+	if cfg.File == "" {
+		return config.EnvFile, config.SetEnv(config.EnvFile, map[string]string{
+			"TRELLO_API_KEY":   cfg.Trello.APIKey,
+			"TRELLO_API_TOKEN": token,
+		})
+	}
+
+	err := config.SetFile(cfg.File, "trello", map[string]string{
+		"api_key":   cfg.Trello.APIKey,
+		"api_token": token,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// The environment, including .env, overrides the config file.
+	for _, name := range []string{"TRELLO_API_KEY", "TRELLO_API_TOKEN"} {
+		if os.Getenv(name) != "" {
+			logger.Warnf("%s is set in the environment or %s and takes precedence over %s, remove it there", name, config.EnvFile, cfg.File)
+		}
+	}
+
+	return cfg.File, nil
+}
+
+// authHandler serves authPage and sends the token it posts with state to
+// tokens.
+func authHandler(state string, tokens chan<- string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, authPage)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.PostFormValue("state") != state {
+			http.Error(w, "This is not the authorization trello-vikunja is waiting for.", http.StatusBadRequest)
+			return
+		}
+		token := r.PostFormValue("token")
+		if token == "" {
+			http.Error(w, "Trello sent no token, the authorization was probably denied.", http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case tokens <- token:
+		default:
+		}
+		fmt.Fprint(w, "trello-vikunja got the token, you can close this page.")
+	})
+
+	return mux
+}
+
+func validTokenExpiration(expiration string) bool {
+	for _, valid := range trellosource.TokenExpirations {
+		if expiration == valid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// randomState returns the value which tells the token posted back from
+// requests of other pages.
+func randomState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// openBrowser opens url in the default browser.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	case "darwin":
+		cmd = exec.Command("open", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+
+	return cmd.Start()
+}
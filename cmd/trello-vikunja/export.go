@@ -0,0 +1,312 @@
+package main
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/warrenwingaru/go-trello"
+	"golang.org/x/time/rate"
+	"io"
+	"os"
+	"time"
+	"wingaru.me/trello-migrate/internal/config"
+	"wingaru.me/trello-migrate/internal/logging"
+	"wingaru.me/trello-migrate/internal/progress"
+	"wingaru.me/trello-migrate/pkg/trellosource"
+)
+
+// trelloRateLimit is the rate go-trello limits its requests to.
+const trelloRateLimit = rate.Limit(8)
+
+func runExport(cfg *config.Config) error {
+	// An incremental export only exports boards and cards which changed
+	// since the last run and merges them into the existing export. The cards
+	// of a changed board are still listed in full, Trello can't filter them
+	// by activity.
+	incremental := cfg.Export.Incremental
+	client := trello.NewClient(cfg.Trello.APIKey, cfg.Trello.APIToken)
+	client.Logger = logger
+	startProgress(trelloRateLimit)
+	defer bars.Wait()
+	client.Client = bars.HTTPClient()
+
+	token, err := trellosource.CheckToken(client)
+	if err != nil {
+		return err
+	}
+	if token.DateExpires != nil {
+		logger.Infof("The Trello token expires at %s", token.DateExpires.Format(time.RFC1123))
+	}
+
+	source := trellosource.NewAPISource(client)
+	source.History = cfg.Export.History
+	source.Logger = logger
+
+	if cfg.Files.AttachmentsDir != "" {
+		err := os.MkdirAll(cfg.Files.AttachmentsDir, 0755)
+		if err != nil {
+			return err
+		}
+	}
+
+	boards, err := getTrelloBoards(client)
+	if err != nil {
+		return err
+	}
+
+	state := &exportState{Watermarks: map[string]time.Time{}}
+	var previous []*trellosource.Board
+	if incremental {
+		state, err = readExportState(cfg.Files.ExportState)
+		if err != nil {
+			return err
+		}
+		previous, err = readPreviousExport(cfg.Files.Trello)
+		if err != nil {
+			return err
+		}
+		forgetUnexportedBoards(state, previous, cfg.Files.Trello)
+	}
+
+	boardBar := bars.Add("boards", len(boards), progress.Boards)
+	defer boardBar.Done()
+
+	fetched := make(map[string]*trellosource.Board, len(boards))
+	organizationMap := getTrelloOrganizationsWithBoards(boards)
+	for organizationID, boards := range organizationMap {
+		logger.WithField("organization_id", organizationID).Debug("Getting organization")
+		orgName := organizationID
+		if orgName != "Personal" {
+			organization, err := client.GetOrganization(organizationID, trello.Defaults())
+			if err != nil {
+				return err
+			}
+			orgName = organization.DisplayName
+		}
+
+		for _, board := range boards {
+			boardBar.Increment()
+			var since, latest time.Time
+			if incremental {
+				watermark, hasWatermark := state.Watermarks[board.ID]
+				var changed bool
+				latest, changed, err = getLatestBoardActionDate(board, watermark)
+				if err != nil {
+					return err
+				}
+				if hasWatermark && !changed {
+					logger.WithField(logging.BoardID, board.ID).Infof("Board %s did not change since %s, skipping", board.Name, watermark)
+					continue
+				}
+				since = watermark
+			}
+
+			logger.WithField(logging.BoardID, board.ID).Infof("Exporting board %s of %s", board.Name, orgName)
+
+			exportedBoard := &trellosource.Board{Board: board}
+			err = fillCardData(source, exportedBoard, since, cfg.Files.AttachmentsDir)
+			if err != nil {
+				return err
+			}
+
+			exportedBoard.CustomFields, err = board.GetCustomFields(trello.Defaults())
+			if err != nil {
+				return err
+			}
+			fetched[board.ID] = exportedBoard
+			if incremental {
+				state.Watermarks[board.ID] = latest
+			}
+			logger.WithField(logging.BoardID, board.ID).Debug("Exported board")
+		}
+
+		//hiararchy, err := convertTrelloToVikunja(boards, vikunjaData)
+		//if err != nil {
+		//	return err
+		//}
+		//hiarachies = append(hiarachies, hiararchy)
+	}
+
+	exported := make([]*trellosource.Board, 0, len(fetched))
+	for _, board := range boards {
+		if b, exists := fetched[board.ID]; exists {
+			exported = append(exported, b)
+		}
+	}
+
+	if incremental {
+		exported = mergeExports(previous, exported)
+	}
+
+	err = trellosource.WriteFile(cfg.Files.Trello, exported)
+	if err != nil {
+		return err
+	}
+
+	if incremental {
+		err = writeExportState(cfg.Files.ExportState, state)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func getTrelloBoards(client *trello.Client) (trelloData []*trello.Board, err error) {
+	logger.Info("Getting boards")
+
+	trelloData, err = client.GetMyBoards(trello.Defaults())
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Got %d Trello boards", len(trelloData))
+
+	return
+}
+
+func getTrelloOrganizationsWithBoards(boards []*trello.Board) (boardsByOrg map[string][]*trello.Board) {
+
+	boardsByOrg = make(map[string][]*trello.Board)
+
+	for _, board := range boards {
+		// Trello boards without an organization are considered personal boards
+		if board.IDOrganization == "" {
+			board.IDOrganization = "Personal"
+		}
+
+		_, has := boardsByOrg[board.IDOrganization]
+		if !has {
+			boardsByOrg[board.IDOrganization] = []*trello.Board{}
+		}
+
+		boardsByOrg[board.IDOrganization] = append(boardsByOrg[board.IDOrganization], board)
+	}
+
+	return
+}
+
+// fillCardData loads the lists of a board and the archived cards in them.
+// When since is set, only cards with activity after it are loaded, after
+// listing all cards of the board. When attachmentsDir is set, the files of
+// uploaded attachments are saved there.
+func fillCardData(source *trellosource.APISource, board *trellosource.Board, since time.Time, attachmentsDir string) (err error) {
+	boardLogger := logger.WithField(logging.BoardID, board.ID)
+	boardLogger.Debug("Getting lists")
+
+	// We'll process this differently
+	board.Lists, err = source.Lists(board)
+	if err != nil {
+		return err
+	}
+
+	boardLogger.Debugf("Got %d lists", len(board.Lists))
+
+	listMap := make(map[string]*trello.List, len(board.Lists))
+	for _, list := range board.Lists {
+		listMap[list.ID] = list
+	}
+
+	boardLogger.Debug("Getting cards")
+
+	cards, err := source.AllCards(board.Board)
+	if err != nil {
+		return
+	}
+
+	boardLogger.Debugf("Got %d cards", len(cards))
+
+	cardBar := bars.Add(board.Name, len(cards), progress.Cards)
+	defer cardBar.Done()
+
+	for _, card := range cards {
+		cardBar.Increment()
+		cardLogger := boardLogger.WithField(logging.CardID, card.ID)
+		list, exists := listMap[card.IDList]
+		if !exists {
+			continue
+		}
+
+		if !since.IsZero() && card.DateLastActivity != nil && !card.DateLastActivity.After(since) {
+			continue
+		}
+
+		if !trellosource.Archived(list, card) {
+			cardLogger.Tracef("Skipped open card %s of list %s", card.Name, list.Name)
+			continue
+		}
+
+		cardLogger.Debugf("Exporting card %s of list %s", card.Name, list.Name)
+		err := processCard(source, board, card, attachmentsDir)
+		if err != nil {
+			return err
+		}
+		list.Cards = append(list.Cards, card)
+	}
+
+	return
+}
+
+func processCard(source *trellosource.APISource, board *trellosource.Board, card *trello.Card, attachmentsDir string) (err error) {
+	err = source.LoadCard(board, card)
+	if err != nil {
+		return
+	}
+
+	card.Actions, err = source.Comments(card)
+	if err != nil {
+		return
+	}
+
+	if attachmentsDir != "" {
+		return saveAttachments(source, card, attachmentsDir)
+	}
+
+	return
+}
+
+// saveAttachments saves the files of the uploaded attachments of card to
+// dir, named by attachment id. Files saved by an earlier run are kept.
+func saveAttachments(source *trellosource.APISource, card *trello.Card, dir string) error {
+	attachments := trellosource.Attachments{Dir: dir}
+	for _, attachment := range card.Attachments {
+		if !attachment.IsUpload {
+			continue
+		}
+
+		path := attachments.Path(attachment)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+
+		err := saveAttachment(source, attachment, path)
+		if err != nil {
+			return err
+		}
+		logger.WithFields(logrus.Fields{logging.CardID: card.ID, "attachment_id": attachment.ID}).Debug("Saved attachment")
+	}
+
+	return nil
+}
+
+func saveAttachment(source *trellosource.APISource, attachment *trello.Attachment, path string) error {
+	content, err := source.Attachment(attachment)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+
+	return err
+}
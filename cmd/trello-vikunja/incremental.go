@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/warrenwingaru/go-trello"
+	"wingaru.me/trello-migrate/internal/logging"
+	"wingaru.me/trello-migrate/pkg/trellosource"
+)
+
+// exportState is persisted between incremental runs. It maps a board id to
+// the date of the last action seen on that board.
+type exportState struct {
+	Watermarks map[string]time.Time `json:"watermarks"`
+}
+
+func readExportState(filename string) (*exportState, error) {
+	state := &exportState{Watermarks: map[string]time.Time{}}
+
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	if state.Watermarks == nil {
+		state.Watermarks = map[string]time.Time{}
+	}
+
+	return state, nil
+}
+
+func writeExportState(filename string, state *exportState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, data, 0644)
+}
+
+// readPreviousExport reads the boards of an earlier export. A missing file is
+// not an error, it just means there is nothing to merge with.
+func readPreviousExport(filename string) ([]*trellosource.Board, error) {
+	boards, err := trellosource.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	return boards, err
+}
+
+// forgetUnexportedBoards drops the watermarks of boards which are not in the
+// previous export, like when trello.json was deleted or replaced, so they are
+// exported in full again instead of being left out.
+func forgetUnexportedBoards(state *exportState, previous []*trellosource.Board, filename string) {
+	exported := make(map[string]bool, len(previous))
+	for _, board := range previous {
+		exported[board.ID] = true
+	}
+
+	for boardID := range state.Watermarks {
+		if !exported[boardID] {
+			logger.WithField(logging.BoardID, boardID).Warnf("Board is not in %s, exporting it in full", filename)
+			delete(state.Watermarks, boardID)
+		}
+	}
+}
+
+// getLatestBoardActionDate returns the date of the newest action on a board
+// after since. changed is false when nothing happened on the board since then.
+func getLatestBoardActionDate(board *trello.Board, since time.Time) (latest time.Time, changed bool, err error) {
+	args := trello.Arguments{"limit": "1"}
+	if !since.IsZero() {
+		args["since"] = since.Format(time.RFC3339Nano)
+	}
+
+	actions, err := board.GetActions(args)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	if len(actions) == 0 {
+		return since, false, nil
+	}
+
+	return actions[0].Date, true, nil
+}
+
+// mergeBoard merges the lists and cards of a freshly fetched board into the
+// same board from a previous export. Cards fetched in this run replace their
+// older copies, all other previously exported cards are kept.
+func mergeBoard(previous, current *trellosource.Board) {
+	cards := make(map[string]*trello.Card)
+	var order []string
+	addCard := func(card *trello.Card) {
+		if _, exists := cards[card.ID]; !exists {
+			order = append(order, card.ID)
+		}
+		cards[card.ID] = card
+	}
+
+	for _, list := range previous.Lists {
+		for _, card := range list.Cards {
+			addCard(card)
+		}
+	}
+	fetchedItems := make(map[string]bool)
+	for _, list := range current.Lists {
+		for _, card := range list.Cards {
+			addCard(card)
+			for _, checklist := range card.Checklists {
+				for _, item := range checklist.CheckItems {
+					fetchedItems[item.ID] = true
+				}
+			}
+		}
+	}
+
+	lists := make(map[string]*trello.List, len(current.Lists))
+	for _, list := range current.Lists {
+		list.Cards = nil
+		lists[list.ID] = list
+	}
+
+	// Keep lists which were deleted on Trello but still hold exported cards
+	for _, list := range previous.Lists {
+		if _, exists := lists[list.ID]; !exists {
+			list.Cards = nil
+			lists[list.ID] = list
+			current.Lists = append(current.Lists, list)
+		}
+	}
+
+	for _, id := range order {
+		card := cards[id]
+		list, exists := lists[card.IDList]
+		if !exists {
+			continue
+		}
+		list.Cards = append(list.Cards, card)
+	}
+
+	// Keep the checklist item details of the cards which weren't fetched again
+	for id, details := range previous.CheckItems {
+		if !fetchedItems[id] {
+			if current.CheckItems == nil {
+				current.CheckItems = make(map[string]trellosource.CheckItemDetails)
+			}
+			current.CheckItems[id] = details
+		}
+	}
+}
+
+// mergeExports combines the boards of a previous export with the boards of
+// this run. Boards which were not fetched again are kept as they were.
+func mergeExports(previous, current []*trellosource.Board) []*trellosource.Board {
+	previousByID := make(map[string]*trellosource.Board, len(previous))
+	for _, board := range previous {
+		previousByID[board.ID] = board
+	}
+
+	merged := make([]*trellosource.Board, 0, len(current))
+	seen := make(map[string]bool, len(current))
+	for _, board := range current {
+		if old, exists := previousByID[board.ID]; exists {
+			mergeBoard(old, board)
+		}
+		merged = append(merged, board)
+		seen[board.ID] = true
+	}
+
+	for _, board := range previous {
+		if !seen[board.ID] {
+			merged = append(merged, board)
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"io"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/warrenwingaru/go-trello"
+	"wingaru.me/trello-migrate/internal/logging"
+	"wingaru.me/trello-migrate/pkg/trellosource"
+)
+
+// cardNames returns the names of the cards of board by list.
+func cardNames(board *trellosource.Board) map[string][]string {
+	names := make(map[string][]string)
+	for _, list := range board.Lists {
+		for _, card := range list.Cards {
+			names[list.ID] = append(names[list.ID], card.Name)
+		}
+	}
+
+	return names
+}
+
+func TestMergeExports(t *testing.T) {
+	previous := []*trellosource.Board{
+		{Board: &trello.Board{ID: "board1", Lists: []*trello.List{
+			{ID: "list1", Cards: []*trello.Card{
+				{ID: "card1", IDList: "list1", Name: "old card1"},
+				{ID: "card2", IDList: "list1", Name: "card2"},
+			}},
+			{ID: "deleted", Cards: []*trello.Card{
+				{ID: "card3", IDList: "deleted", Name: "card3"},
+			}},
+		}}},
+		{Board: &trello.Board{ID: "board2", Lists: []*trello.List{
+			{ID: "list2", Cards: []*trello.Card{{ID: "card4", IDList: "list2", Name: "card4"}}},
+		}}},
+	}
+	current := []*trellosource.Board{
+		{Board: &trello.Board{ID: "board1", Lists: []*trello.List{
+			{ID: "list1", Cards: []*trello.Card{
+				{ID: "card1", IDList: "list1", Name: "new card1"},
+			}},
+			{ID: "list3", Cards: []*trello.Card{
+				{ID: "card5", IDList: "list3", Name: "card5"},
+			}},
+		}}},
+		{Board: &trello.Board{ID: "board3"}},
+	}
+
+	merged := mergeExports(previous, current)
+
+	var ids []string
+	for _, board := range merged {
+		ids = append(ids, board.ID)
+	}
+	if want := []string{"board1", "board3", "board2"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("merged the boards %v, want %v", ids, want)
+	}
+
+	want := map[string][]string{
+		"list1":   {"new card1", "card2"},
+		"list3":   {"card5"},
+		"deleted": {"card3"},
+	}
+	if got := cardNames(merged[0]); !reflect.DeepEqual(got, want) {
+		t.Errorf("the merged board has the cards %v, want %v", got, want)
+	}
+	if got := cardNames(merged[2]); !reflect.DeepEqual(got, map[string][]string{"list2": {"card4"}}) {
+		t.Errorf("the board which wasn't fetched again has the cards %v, want it unchanged", got)
+	}
+}
+
+func TestForgetUnexportedBoards(t *testing.T) {
+	logger = &logging.Logger{Logger: &logrus.Logger{Out: io.Discard, Formatter: new(logrus.TextFormatter), Level: logrus.InfoLevel}}
+
+	watermark := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	state := &exportState{Watermarks: map[string]time.Time{"board1": watermark, "board2": watermark}}
+	previous := []*trellosource.Board{{Board: &trello.Board{ID: "board1"}}}
+
+	forgetUnexportedBoards(state, previous, "trello.json")
+	if want := map[string]time.Time{"board1": watermark}; !reflect.DeepEqual(state.Watermarks, want) {
+		t.Errorf("the watermarks are %v, want %v", state.Watermarks, want)
+	}
+
+	// Without a previous export, every board is exported in full.
+	forgetUnexportedBoards(state, nil, "trello.json")
+	if len(state.Watermarks) != 0 {
+		t.Errorf("the watermarks are %v, want none", state.Watermarks)
+	}
+}
+
+func TestExportState(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "trello.state.json")
+
+	state, err := readExportState(filename)
+	if err != nil {
+		t.Fatalf("reading a missing state: %v", err)
+	}
+	if len(state.Watermarks) != 0 {
+		t.Fatalf("a missing state has the watermarks %v, want none", state.Watermarks)
+	}
+
+	state.Watermarks["board1"] = time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	if err := writeExportState(filename, state); err != nil {
+		t.Fatalf("writeExportState: %v", err)
+	}
+
+	read, err := readExportState(filename)
+	if err != nil {
+		t.Fatalf("readExportState: %v", err)
+	}
+	if !read.Watermarks["board1"].Equal(state.Watermarks["board1"]) || len(read.Watermarks) != 1 {
+		t.Errorf("read the watermarks %v, want %v", read.Watermarks, state.Watermarks)
+	}
+
+	missing, err := readPreviousExport(filepath.Join(t.TempDir(), "trello.json"))
+	if err != nil || missing != nil {
+		t.Errorf("readPreviousExport of a missing file = %v, %v, want nothing to merge", missing, err)
+	}
+}
+
+func TestMergeBoardCheckItems(t *testing.T) {
+	due := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	previous := &trellosource.Board{
+		Board: &trello.Board{ID: "board1", Lists: []*trello.List{{ID: "list1", Cards: []*trello.Card{
+			{ID: "card1", IDList: "list1", Checklists: []*trello.Checklist{{CheckItems: []trello.CheckItem{{ID: "item1"}}}}},
+			{ID: "card2", IDList: "list1", Checklists: []*trello.Checklist{{CheckItems: []trello.CheckItem{{ID: "item2"}}}}},
+		}}}},
+		CheckItems: map[string]trellosource.CheckItemDetails{
+			"item1": {Due: &due},
+			"item2": {IDMember: "member1"},
+		},
+	}
+	// card1 was fetched again after the due date of its item was removed.
+	current := &trellosource.Board{
+		Board: &trello.Board{ID: "board1", Lists: []*trello.List{{ID: "list1", Cards: []*trello.Card{
+			{ID: "card1", IDList: "list1", Checklists: []*trello.Checklist{{CheckItems: []trello.CheckItem{{ID: "item1"}}}}},
+		}}}},
+	}
+
+	mergeBoard(previous, current)
+
+	want := map[string]trellosource.CheckItemDetails{"item2": {IDMember: "member1"}}
+	if !reflect.DeepEqual(current.CheckItems, want) {
+		t.Errorf("the merged board has the check items %+v, want %+v", current.CheckItems, want)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"wingaru.me/trello-migrate/internal/config"
+	"wingaru.me/trello-migrate/internal/migration"
+	"wingaru.me/trello-migrate/pkg/models"
+	"wingaru.me/trello-migrate/pkg/vikunja"
+)
+
+// trelloLinkPattern matches links to Trello boards (b) and cards (c),
+// including the optional slug after the short link.
+var trelloLinkPattern = regexp.MustCompile(`https?://trello\.com/([bc])/([A-Za-z0-9]+)(?:/[^\s"'<>)]*)?`)
+
+// linkRewrite is a single Trello link which was replaced by a Vikunja link.
+type linkRewrite struct {
+	TaskID    int64
+	CommentID int64
+	From      string
+	To        string
+}
+
+// rewriteTrelloLinks replaces every link to a migrated Trello board or card
+// in input with a link to the Vikunja project or task it became. Links to
+// anything not in the journal are left untouched.
+func rewriteTrelloLinks(input string, journal *migration.Journal, frontendURL string) (output string, rewrites []linkRewrite) {
+	output = trelloLinkPattern.ReplaceAllStringFunc(input, func(link string) string {
+		match := trelloLinkPattern.FindStringSubmatch(link)
+
+		var to string
+		switch match[1] {
+		case "b":
+			projectID, found := journal.ProjectForShortLink(match[2])
+			if !found {
+				return link
+			}
+			to = fmt.Sprintf("%s/projects/%d", frontendURL, projectID)
+		case "c":
+			taskID, found := journal.TaskForShortLink(match[2])
+			if !found {
+				return link
+			}
+			to = fmt.Sprintf("%s/tasks/%d", frontendURL, taskID)
+		}
+
+		rewrites = append(rewrites, linkRewrite{From: link, To: to})
+		return to
+	})
+
+	return output, rewrites
+}
+
+// rewriteMigratedLinks runs over all uploaded tasks and comments once every
+// board was migrated and points their Trello links to Vikunja instead.
+func rewriteMigratedLinks(client *vikunja.Client, projects []*models.ProjectWithTasksAndBuckets, journal *migration.Journal, frontendURL string) (rewrites []linkRewrite, err error) {
+	var rewriteTask func(task *models.TaskWithComments) error
+	rewriteTask = func(task *models.TaskWithComments) error {
+		description, taskRewrites := rewriteTrelloLinks(task.Description, journal, frontendURL)
+		if len(taskRewrites) > 0 {
+			task.Description = description
+			if err := client.UpdateTask(&task.Task); err != nil {
+				return err
+			}
+			for _, rewrite := range taskRewrites {
+				rewrite.TaskID = task.ID
+				rewrites = append(rewrites, rewrite)
+			}
+		}
+
+		for _, comment := range task.Comments {
+			text, commentRewrites := rewriteTrelloLinks(comment.Comment, journal, frontendURL)
+			if len(commentRewrites) == 0 {
+				continue
+			}
+
+			comment.Comment = text
+			if err := client.UpdateTaskComment(comment); err != nil {
+				return err
+			}
+			for _, rewrite := range commentRewrites {
+				rewrite.TaskID = task.ID
+				rewrite.CommentID = comment.ID
+				rewrites = append(rewrites, rewrite)
+			}
+		}
+
+		for _, subtask := range task.Subtasks {
+			if err := rewriteTask(subtask); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for _, project := range projects {
+		for _, bucket := range project.Buckets {
+			for _, task := range bucket.TasksWithComments {
+				if err := rewriteTask(task); err != nil {
+					return rewrites, err
+				}
+			}
+		}
+	}
+
+	return rewrites, nil
+}
+
+// resolveFrontendURL returns the configured url of the Vikunja frontend,
+// else the one the instance reports in info, else a guess.
+func resolveFrontendURL(cfg *config.Config, info *vikunja.Info) string {
+	if cfg.Vikunja.FrontendURL != "" {
+		return cfg.Vikunja.FrontendURL
+	}
+	if info != nil && info.FrontendURL != "" {
+		return strings.TrimSuffix(info.FrontendURL, "/")
+	}
+
+	return vikunjaFrontendURL(cfg.Vikunja.Instance)
+}
+
+// vikunjaFrontendURL guesses the url of the Vikunja frontend from the url of
+// its api.
+func vikunjaFrontendURL(apiURL string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(apiURL, "/"), "/api/v1")
+}
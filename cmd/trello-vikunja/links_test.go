@@ -0,0 +1,99 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"wingaru.me/trello-migrate/internal/migration"
+)
+
+func TestRewriteTrelloLinks(t *testing.T) {
+	journal := &migration.Journal{
+		Boards: map[string]*migration.JournalBoard{
+			"board1": {ShortLink: "bOne", ProjectID: 3},
+		},
+		Cards: map[string]*migration.JournalCard{
+			"card1": {ShortLink: "cOne", TaskID: 20},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		from  []string
+	}{
+		{
+			"card",
+			"See https://trello.com/c/cOne for details",
+			"See https://vikunja.example/tasks/20 for details",
+			[]string{"https://trello.com/c/cOne"},
+		},
+		{
+			"card with slug",
+			"[Task](https://trello.com/c/cOne/12-write-the-docs)",
+			"[Task](https://vikunja.example/tasks/20)",
+			[]string{"https://trello.com/c/cOne/12-write-the-docs"},
+		},
+		{
+			"board over http",
+			"http://trello.com/b/bOne/roadmap",
+			"https://vikunja.example/projects/3",
+			[]string{"http://trello.com/b/bOne/roadmap"},
+		},
+		{
+			"html attribute",
+			`<a href="https://trello.com/c/cOne">card</a>`,
+			`<a href="https://vikunja.example/tasks/20">card</a>`,
+			[]string{"https://trello.com/c/cOne"},
+		},
+		{
+			"not migrated",
+			"https://trello.com/c/cTwo and https://trello.com/b/bTwo",
+			"https://trello.com/c/cTwo and https://trello.com/b/bTwo",
+			nil,
+		},
+		{
+			"several",
+			"https://trello.com/c/cOne, https://trello.com/c/cTwo, https://trello.com/b/bOne",
+			"https://vikunja.example/tasks/20, https://trello.com/c/cTwo, https://vikunja.example/projects/3",
+			[]string{"https://trello.com/c/cOne", "https://trello.com/b/bOne"},
+		},
+		{
+			"other hosts",
+			"https://example.com/c/cOne",
+			"https://example.com/c/cOne",
+			nil,
+		},
+	}
+	for _, test := range tests {
+		output, rewrites := rewriteTrelloLinks(test.input, journal, "https://vikunja.example")
+		if output != test.want {
+			t.Errorf("%s: rewriteTrelloLinks(%q) = %q, want %q", test.name, test.input, output, test.want)
+		}
+
+		var from []string
+		for _, rewrite := range rewrites {
+			from = append(from, rewrite.From)
+		}
+		if !reflect.DeepEqual(from, test.from) {
+			t.Errorf("%s: rewrote %q, want %q", test.name, from, test.from)
+		}
+	}
+}
+
+func TestVikunjaFrontendURL(t *testing.T) {
+	tests := []struct {
+		apiURL string
+		want   string
+	}{
+		{"https://vikunja.example/api/v1", "https://vikunja.example"},
+		{"https://vikunja.example/api/v1/", "https://vikunja.example"},
+		{"https://api.vikunja.example", "https://api.vikunja.example"},
+	}
+	for _, test := range tests {
+		if got := vikunjaFrontendURL(test.apiURL); got != test.want {
+			t.Errorf("vikunjaFrontendURL(%q) = %q, want %q", test.apiURL, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"wingaru.me/trello-migrate/internal/config"
+	"wingaru.me/trello-migrate/internal/logging"
+)
+
+const usage = `Usage: trello-vikunja <command> [flags]
+
+Commands:
+  export    Export archived Trello cards into the trello file
+  plan      Show what migrate would do, without changing anything
+  migrate   Migrate the exported cards into Vikunja
+  verify    Compare the migrated tasks with the export
+  rollback  Delete everything recorded in the journal from Vikunja
+  auth trello
+            Authorize the exporter on Trello and store the token in .env
+
+Every command accepts -config with the path of a yaml or toml config file.
+Environment variables (and a .env file, if present) override the config
+file, flags override both. Run "trello-vikunja <command> -h" for its flags.
+`
+
+type command struct {
+	run   func(cfg *config.Config) error
+	flags []func(fs *flag.FlagSet, cfg *config.Config)
+}
+
+var commands = map[string]command{
+	"export":   {run: runExport, flags: flagGroups(trelloFlags, fileFlags, exportFlags, logFlags)},
+	"plan":     {run: runPlan, flags: flagGroups(vikunjaFlags, fileFlags, migrateFlags, logFlags)},
+	"migrate":  {run: runMigrate, flags: flagGroups(trelloFlags, vikunjaFlags, fileFlags, migrateFlags, logFlags)},
+	"verify":   {run: runVerify, flags: flagGroups(trelloFlags, vikunjaFlags, fileFlags, migrateFlags, logFlags)},
+	"rollback": {run: runRollback, flags: flagGroups(vikunjaFlags, fileFlags, logFlags)},
+
+	"auth trello": {run: runAuthTrello, flags: flagGroups(trelloFlags, authFlags, logFlags)},
+}
+
+// logger is the logger of the running command, shared with every client.
+var logger *logging.Logger
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	name, args := os.Args[1], os.Args[2:]
+	// Commands like "auth trello" take the name of the service as a second
+	// word.
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		if _, exists := commands[name+" "+args[0]]; exists {
+			name, args = name+" "+args[0], args[1:]
+		}
+	}
+	cmd, exists := commands[name]
+	if !exists {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", name, usage)
+		os.Exit(2)
+	}
+
+	cfg, err := parseConfig(name, cmd, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	logger, err = logging.New(cfg.Log)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	err = cmd.run(cfg)
+	if err != nil {
+		logger.WithError(err).Errorf("%s failed", name)
+	}
+	logger.Close()
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// parseConfig parses args twice: first only to find the config file, then,
+// after loading the config file and the environment, to let the flags
+// override them.
+func parseConfig(name string, cmd command, args []string) (*config.Config, error) {
+	newFlagSet := func(cfg *config.Config, configFile *string) *flag.FlagSet {
+		fs := flag.NewFlagSet(name, flag.ContinueOnError)
+		fs.StringVar(configFile, "config", "", "path of a yaml or toml config file")
+		for _, register := range cmd.flags {
+			register(fs, cfg)
+		}
+		return fs
+	}
+
+	var configFile string
+	probe := newFlagSet(config.Default(), &configFile)
+	if err := probe.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := newFlagSet(cfg, &configFile).Parse(args); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func flagGroups(groups ...func(fs *flag.FlagSet, cfg *config.Config)) []func(fs *flag.FlagSet, cfg *config.Config) {
+	return groups
+}
+
+func trelloFlags(fs *flag.FlagSet, cfg *config.Config) {
+	fs.StringVar(&cfg.Trello.APIKey, "trello-key", cfg.Trello.APIKey, "Trello api key")
+	fs.StringVar(&cfg.Trello.APIToken, "trello-token", cfg.Trello.APIToken, "Trello api token")
+}
+
+func authFlags(fs *flag.FlagSet, cfg *config.Config) {
+	fs.StringVar(&cfg.Trello.TokenExpiration, "token-expiration", cfg.Trello.TokenExpiration, "how long the token is valid: 1hour, 1day, 30days or never")
+	fs.IntVar(&cfg.Trello.CallbackPort, "callback-port", cfg.Trello.CallbackPort, "local port Trello sends the token to")
+}
+
+func vikunjaFlags(fs *flag.FlagSet, cfg *config.Config) {
+	fs.StringVar(&cfg.Vikunja.Instance, "vikunja-instance", cfg.Vikunja.Instance, "Vikunja api url, like https://vikunja.tld/api/v1")
+	fs.StringVar(&cfg.Vikunja.APIKey, "vikunja-key", cfg.Vikunja.APIKey, "Vikunja api token")
+	fs.StringVar(&cfg.Vikunja.Username, "vikunja-username", cfg.Vikunja.Username, "Vikunja user to log in as when -vikunja-key is empty")
+	fs.StringVar(&cfg.Vikunja.Password, "vikunja-password", cfg.Vikunja.Password, "password of -vikunja-username")
+	fs.StringVar(&cfg.Vikunja.TOTP, "vikunja-totp", cfg.Vikunja.TOTP, "current two factor passcode of -vikunja-username")
+	fs.BoolVar(&cfg.Vikunja.ScopedToken, "vikunja-scoped-token", cfg.Vikunja.ScopedToken, "migrate with an api token created from the login, deleted afterwards")
+	fs.StringVar(&cfg.Vikunja.FrontendURL, "vikunja-frontend-url", cfg.Vikunja.FrontendURL, "Vikunja frontend url, derived from -vikunja-instance when empty")
+}
+
+func fileFlags(fs *flag.FlagSet, cfg *config.Config) {
+	fs.StringVar(&cfg.Files.Trello, "trello-file", cfg.Files.Trello, "path of the Trello export")
+	// Flags replace the exports of the config file instead of adding to them.
+	var uiExportFlagSeen bool
+	fs.Func("trello-ui-export", "path of a board exported from the Trello UI, read instead of -trello-file; repeat or separate with commas", func(value string) error {
+		if !uiExportFlagSeen {
+			cfg.Files.TrelloUIExports = nil
+			uiExportFlagSeen = true
+		}
+		cfg.Files.TrelloUIExports = append(cfg.Files.TrelloUIExports, config.SplitList(value)...)
+		return nil
+	})
+	fs.StringVar(&cfg.Files.AttachmentsDir, "attachments-dir", cfg.Files.AttachmentsDir, "directory the exporter saves attachment files to and the migrator reads them from, empty to download them during migrate")
+	fs.StringVar(&cfg.Files.ExportState, "export-state", cfg.Files.ExportState, "path of the incremental export state")
+	fs.StringVar(&cfg.Files.Data, "data-file", cfg.Files.Data, "path of the Vikunja export zip or the data.json in it")
+	fs.StringVar(&cfg.Files.Journal, "journal", cfg.Files.Journal, "path of the migration journal")
+	fs.StringVar(&cfg.Files.ReportMarkdown, "report-markdown", cfg.Files.ReportMarkdown, "path of the markdown report written by migrate, empty to skip it")
+	fs.StringVar(&cfg.Files.ReportJSON, "report-json", cfg.Files.ReportJSON, "path of the json report written by migrate, empty to skip it")
+	fs.StringVar(&cfg.Files.OutputArchive, "output-archive", cfg.Files.OutputArchive, "write a zip for Vikunja's import instead of uploading, like out.zip")
+	fs.StringVar(&cfg.Files.VerifyReport, "verify-report", cfg.Files.VerifyReport, "path of the json report written by verify")
+}
+
+func exportFlags(fs *flag.FlagSet, cfg *config.Config) {
+	fs.BoolVar(&cfg.Export.History, "history", cfg.Export.History, "export the full activity history of cards")
+	fs.BoolVar(&cfg.Export.Incremental, "incremental", cfg.Export.Incremental, "only export what changed since the last run")
+}
+
+func migrateFlags(fs *flag.FlagSet, cfg *config.Config) {
+	fs.Func("boards", "comma separated names of the boards to migrate, asks when empty", func(value string) error {
+		cfg.Migrate.Boards = config.SplitList(value)
+		return nil
+	})
+	fs.StringVar(&cfg.Migrate.HistoryMode, "history-mode", cfg.Migrate.HistoryMode, "where to put card history: comment, description or empty")
+	fs.StringVar(&cfg.Migrate.BucketStrategy, "bucket-strategy", cfg.Migrate.BucketStrategy, "how to put tasks into buckets: size or list")
+	fs.StringVar(&cfg.Migrate.OversizePolicy, "oversize-policy", cfg.Migrate.OversizePolicy, "what to do with attachments larger than Vikunja accepts: link, zip or fail")
+	fs.StringVar(&cfg.Migrate.ChecklistMode, "checklist-mode", cfg.Migrate.ChecklistMode, "how to migrate checklists: description or subtasks")
+	fs.StringVar(&cfg.Migrate.CardLinkRelation, "card-link-relation", cfg.Migrate.CardLinkRelation, "relation kind created for card link attachments")
+	fs.StringVar(&cfg.Migrate.CustomFieldsConfig, "custom-fields-config", cfg.Migrate.CustomFieldsConfig, "path of the custom field mapping")
+	fs.StringVar(&cfg.Migrate.HTMLAllowlist, "html-allowlist", cfg.Migrate.HTMLAllowlist, "path of the html allowlist")
+	fs.StringVar(&cfg.Migrate.ColorPalette, "color-palette", cfg.Migrate.ColorPalette, "path of the color palette")
+}
+
+func logFlags(fs *flag.FlagSet, cfg *config.Config) {
+	fs.StringVar(&cfg.Log.Level, "log-level", cfg.Log.Level, "log level: trace, debug, info, warning or error")
+	fs.StringVar(&cfg.Log.Format, "log-format", cfg.Log.Format, "log format: text or json")
+	fs.StringVar(&cfg.Log.File, "log-file", cfg.Log.File, "file to append the log to, besides the console")
+}
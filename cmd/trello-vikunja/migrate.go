@@ -0,0 +1,705 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"wingaru.me/trello-migrate/internal/config"
+	"wingaru.me/trello-migrate/internal/logging"
+	"wingaru.me/trello-migrate/internal/migration"
+	"wingaru.me/trello-migrate/internal/progress"
+	"wingaru.me/trello-migrate/pkg/markup"
+	"wingaru.me/trello-migrate/pkg/models"
+	"wingaru.me/trello-migrate/pkg/palette"
+	"wingaru.me/trello-migrate/pkg/trello2vikunja"
+	"wingaru.me/trello-migrate/pkg/trellosource"
+	"wingaru.me/trello-migrate/pkg/vikunja"
+)
+
+// configure sets up the conversion from cfg.
+func configure(cfg *config.Config) (options trello2vikunja.Options, err error) {
+	options = trello2vikunja.Options{
+		BucketStrategy:   trello2vikunja.BucketStrategy(cfg.Migrate.BucketStrategy),
+		OversizePolicy:   trello2vikunja.OversizePolicy(cfg.Migrate.OversizePolicy),
+		ChecklistMode:    trello2vikunja.ChecklistMode(cfg.Migrate.ChecklistMode),
+		HistoryMode:      trello2vikunja.HistoryMode(cfg.Migrate.HistoryMode),
+		CardLinkRelation: models.RelationKind(cfg.Migrate.CardLinkRelation),
+		Boards:           cfg.Migrate.Boards,
+		HTTPClient:       migration.HTTPClient,
+		Logger:           logger,
+		Progress:         converterProgress{bars},
+	}
+
+	switch options.BucketStrategy {
+	case "", trello2vikunja.BucketsBySize, trello2vikunja.BucketsByList:
+	default:
+		return options, fmt.Errorf("unknown bucket strategy %q, use size or list", cfg.Migrate.BucketStrategy)
+	}
+	switch options.OversizePolicy {
+	case "", trello2vikunja.OversizeLink, trello2vikunja.OversizeZip, trello2vikunja.OversizeFail:
+	default:
+		return options, fmt.Errorf("unknown oversize policy %q, use link, zip or fail", cfg.Migrate.OversizePolicy)
+	}
+	switch options.ChecklistMode {
+	case "", trello2vikunja.ChecklistsDescription, trello2vikunja.ChecklistsSubtasks:
+	default:
+		return options, fmt.Errorf("unknown checklist mode %q, use description or subtasks", cfg.Migrate.ChecklistMode)
+	}
+	switch options.HistoryMode {
+	case "", trello2vikunja.HistoryComment, trello2vikunja.HistoryDescription:
+	default:
+		return options, fmt.Errorf("unknown history mode %q, use comment, description or leave it empty", cfg.Migrate.HistoryMode)
+	}
+	if options.CardLinkRelation != "" && !validRelationKind(options.CardLinkRelation) {
+		return options, fmt.Errorf("unknown card link relation %q, use one of %v", cfg.Migrate.CardLinkRelation, trello2vikunja.RelationKinds())
+	}
+
+	allowlist, err := markup.ReadAllowlist(cfg.Migrate.HTMLAllowlist)
+	if err != nil {
+		return options, err
+	}
+	options.Sanitizer = markup.NewSanitizer(allowlist)
+	options.CustomFields, err = trello2vikunja.ReadCustomFieldConfig(cfg.Migrate.CustomFieldsConfig)
+	if err != nil {
+		return options, err
+	}
+
+	options.Palette, err = palette.Read(cfg.Migrate.ColorPalette)
+	if err != nil {
+		return options, err
+	}
+
+	return options, nil
+}
+
+func validRelationKind(kind models.RelationKind) bool {
+	for _, valid := range trello2vikunja.RelationKinds() {
+		if kind == valid {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getPadding(padding int) string {
+	if padding <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%*s", padding, "")
+}
+
+func readInputUnix() string {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Enter the numbers of board to migrate (1, 3, 4): ")
+	text, _ := reader.ReadString('\n')
+	return text
+}
+
+func readInputWindows() string {
+	fmt.Print("Enter the numbers of board to migrate (1, 3, 4): ")
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Scan()
+	text := scanner.Text()
+	return text
+}
+
+// chooseBoards returns the names of the boards to migrate. Boards set in the
+// configuration are used as they are, otherwise the user is asked.
+func chooseBoards(vikunjaData map[string]models.Project, configured []string) ([]string, error) {
+	if len(configured) > 0 {
+		return configured, nil
+	}
+
+	boardNames := make([]string, 0, len(vikunjaData))
+
+	for name := range vikunjaData {
+		boardNames = append(boardNames, name)
+	}
+	sort.Strings(boardNames)
+
+	// output options
+	maxLength := 0
+	for _, option := range boardNames {
+		if len(option) > maxLength {
+			maxLength = len(option)
+		}
+	}
+
+	for i, option := range boardNames {
+		padding := maxLength - len(option) + 3
+		if i > 8 { // For two-digit indices
+			padding--
+		}
+		if i%2 == 0 {
+			fmt.Printf("%d) %s%s", i+1, option, getPadding(padding))
+		} else {
+			fmt.Printf("%d) %s%s\n", i+1, option, getPadding(padding))
+		}
+	}
+
+	var text string
+	if strings.Contains(strings.ToLower(runtime.GOOS), "windows") {
+		text = readInputWindows()
+	} else {
+		text = readInputUnix()
+	}
+	chosenInStr := strings.Split(strings.TrimSpace(text), ",")
+	chosen := make([]int, 0, len(chosenInStr))
+	for _, str := range chosenInStr {
+		num, err := strconv.Atoi(str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid board number %q: %w", str, err)
+		}
+		chosen = append(chosen, num)
+	}
+
+	boards := make([]string, 0, len(chosen))
+	for _, option := range chosen {
+		if option < 1 || option > len(boardNames) {
+			return nil, fmt.Errorf("there is no board number %d", option)
+		}
+		boards = append(boards, boardNames[option-1])
+	}
+
+	return boards, nil
+}
+
+// prepareMigration reads the Trello export and the Vikunja data and converts
+// the chosen boards. client is used to look up existing labels, it may be nil.
+// Attachments are checked against the max file size of info when it is set.
+func prepareMigration(cfg *config.Config, client *vikunja.Client, info *vikunja.Info, skipDownloads bool) (*trello2vikunja.Plan, error) {
+	options, err := configure(cfg)
+	if err != nil {
+		return nil, err
+	}
+	options.SkipDownloads = skipDownloads
+	if info != nil {
+		options.MaxAttachmentSize, err = info.MaxFileSizeBytes()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	vikunjaData, err := readDataFile(cfg.Files.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	options.Boards, err = chooseBoards(vikunjaData, cfg.Migrate.Boards)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := openTrelloSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	trelloData, err := trellosource.Load(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertBoards(client, source, trelloData, vikunjaData, options)
+}
+
+// convertBoards converts trelloData with options, reusing the labels which
+// already exist in Vikunja when client is set.
+func convertBoards(client *vikunja.Client, source trellosource.Source, trelloData []*trellosource.Board, vikunjaData map[string]models.Project, options trello2vikunja.Options) (*trello2vikunja.Plan, error) {
+	if client != nil {
+		var err error
+		options.Labels, err = client.GetLabels()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return trello2vikunja.NewConverter(source, options).Convert(trelloData, vikunjaData)
+}
+
+func runMigrate(cfg *config.Config) (err error) {
+	if cfg.Files.OutputArchive != "" {
+		return writeArchive(cfg)
+	}
+
+	client, err := newVikunjaClient(cfg)
+	if err != nil {
+		return err
+	}
+	migration.Logger = logger
+	startProgress(client.RateLimit())
+	defer bars.Wait()
+	client.Client = bars.HTTPClient()
+	migration.HTTPClient = bars.HTTPClient()
+
+	if cfg.Vikunja.ScopedToken {
+		release, err := useMigrationToken(cfg, client)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	// Vikunja before projects only has the endpoints of lists.
+	info, err := detectVikunja(client, vikunja.FeatureProjects)
+	if err != nil {
+		return err
+	}
+	frontendURL := resolveFrontendURL(cfg, info)
+
+	report := migration.NewReport()
+	defer func() {
+		report.Finish(err)
+		if writeErr := writeReport(cfg, report); writeErr != nil && err == nil {
+			err = writeErr
+		}
+	}()
+
+	plan, err := prepareMigration(cfg, client, info, false)
+	if err != nil {
+		return err
+	}
+	data := plan.Projects
+
+	if err := client.Require(planFeatures(plan)...); err != nil {
+		return err
+	}
+
+	for _, name := range plan.MissingBoards {
+		report.Skip("board", name, "not in the Trello export")
+	}
+
+	journal, err := migration.OpenJournal(cfg.Files.Journal)
+	if err != nil {
+		return err
+	}
+
+	err = uploadProjects(client, data, journal, report, frontendURL)
+	reportDropped(report, plan)
+	if err != nil {
+		return err
+	}
+
+	relations, err := createCardLinkRelations(client, data, journal, plan.CardLinkRelation, report)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Created %d task relations from card links", relations)
+
+	rewrites, err := rewriteMigratedLinks(client, data, journal, frontendURL)
+	for _, rewrite := range rewrites {
+		entry := logger.WithField(logging.TaskID, rewrite.TaskID)
+		if rewrite.CommentID != 0 {
+			entry = entry.WithField("comment_id", rewrite.CommentID)
+		}
+		entry.Infof("Rewrote %s to %s", rewrite.From, rewrite.To)
+	}
+
+	return err
+}
+
+// reportDropped lists what plan couldn't migrate as it was on the boards it
+// belongs to: the attachments which were too large for Vikunja or couldn't
+// be read, and the due dates and members of checklist items.
+func reportDropped(report *migration.Report, plan *trello2vikunja.Plan) {
+	skip := func(kind string, boardID string, name string, card string, reason string) {
+		name += " on card " + card
+		if board := report.Board(boardID); board != nil {
+			board.Skip(kind, name, reason)
+		} else {
+			report.Skip(kind, name, reason)
+		}
+	}
+
+	for _, attachment := range plan.Oversized {
+		skip("attachment", attachment.BoardID, attachment.Name, attachment.Card, attachment.Reason())
+	}
+	for _, attachment := range plan.LinkedAttachments {
+		skip("attachment", attachment.BoardID, attachment.Name, attachment.Card, attachment.Reason())
+	}
+	for _, item := range plan.DroppedCheckItems {
+		skip("checklist item", item.BoardID, item.Name, item.Card, item.Reason())
+	}
+}
+
+// writeReport writes the report in all configured formats.
+func writeReport(cfg *config.Config, report *migration.Report) error {
+	if cfg.Files.ReportMarkdown != "" {
+		if err := report.WriteMarkdown(cfg.Files.ReportMarkdown); err != nil {
+			return err
+		}
+		logger.Infof("Wrote the migration report to %s", cfg.Files.ReportMarkdown)
+	}
+	if cfg.Files.ReportJSON != "" {
+		if err := report.WriteJSON(cfg.Files.ReportJSON); err != nil {
+			return err
+		}
+		logger.Infof("Wrote the migration report to %s", cfg.Files.ReportJSON)
+	}
+
+	return nil
+}
+
+// uploadProjects creates the buckets, tasks, comments, attachments and
+// subtasks of all projects and records them in the journal. The journal is
+// saved after every task and card and when the upload fails, so an
+// interrupted migration can be rolled back, or resumed by running it again.
+func uploadProjects(client *vikunja.Client, data []*models.ProjectWithTasksAndBuckets, journal *migration.Journal, report *migration.Report, frontendURL string) (err error) {
+	boardBar := bars.Add("uploading boards", len(data), progress.Boards)
+	defer boardBar.Done()
+	defer func() {
+		if err == nil {
+			return
+		}
+		if saveErr := journal.Save(); saveErr != nil {
+			logger.WithError(saveErr).Error("Could not save the journal")
+		}
+	}()
+
+	for _, board := range data {
+		tasks := 0
+		for _, bucket := range board.Buckets {
+			tasks += len(bucket.TasksWithComments)
+		}
+		taskBar := bars.Add(board.Title, tasks, progress.Cards)
+		boardLogger := logger.WithFields(logrus.Fields{logging.BoardID: board.TrelloBoardID, logging.ProjectID: board.ID})
+		boardLogger.Infof("Uploading board %s", board.Title)
+
+		boardReport := report.StartBoard(board.TrelloBoardID, board.Title, board.ID, fmt.Sprintf("%s/projects/%d", frontendURL, board.ID))
+		boardReport.Lists = board.TrelloListCount
+
+		if board.HexColor != "" {
+			project, err := client.GetProject(board.ID)
+			if err != nil {
+				return err
+			}
+			project.HexColor = board.HexColor
+			err = client.UpdateProject(project)
+			if err != nil {
+				return err
+			}
+		}
+
+		boardLogger.Debugf("Uploading %d buckets", len(board.Buckets))
+		for _, bucket := range board.Buckets {
+			// Cards an earlier run migrated completely are not uploaded
+			// again, and left out of the passes after the upload. Cards it
+			// left partial are resumed below.
+			var pending []*models.TaskWithComments
+			for _, task := range bucket.TasksWithComments {
+				if entry, migrated := journal.Cards[task.TrelloCardID]; migrated && !entry.Partial {
+					boardLogger.WithFields(logrus.Fields{logging.CardID: task.TrelloCardID, logging.TaskID: entry.TaskID}).Infof("Skipped card %s, it was already migrated", task.Title)
+					boardReport.Skip("card", task.Title, fmt.Sprintf("already migrated to task %d", entry.TaskID))
+					taskBar.Increment()
+					continue
+				}
+				pending = append(pending, task)
+			}
+			if len(pending) == 0 && len(bucket.TasksWithComments) > 0 {
+				bucket.TasksWithComments = nil
+				continue
+			}
+			bucket.TasksWithComments = pending
+
+			// Reuse the bucket of an earlier run, else create one
+			if bucketID, found := journal.BucketForTitle(board.TrelloBoardID, bucket.ProjectViewID, bucket.Title); found {
+				bucket.ID = bucketID
+				boardLogger.WithField("bucket_id", bucket.ID).Debugf("Reusing bucket %s", bucket.Title)
+			} else {
+				err := client.CreateBucket(bucket)
+				if err != nil {
+					return err
+				}
+				journal.RecordBoard(board.TrelloBoardID, board.TrelloBoardShortLink, board.ID, []migration.JournalBucket{{ID: bucket.ID, ViewID: bucket.ProjectViewID, Title: bucket.Title}})
+				boardReport.Buckets++
+			}
+
+			boardLogger.WithField("bucket_id", bucket.ID).Debugf("Uploading %d tasks", len(bucket.TasksWithComments))
+
+			for _, task := range bucket.TasksWithComments {
+				newTask := &task.Task
+				newTask.BucketID = bucket.ID
+				position := newTask.Position
+
+				journalCard, resumed := journal.Cards[task.TrelloCardID]
+				if resumed {
+					newTask.ID = journalCard.TaskID
+				} else {
+					err := client.AddTask(newTask)
+					if err != nil {
+						return err
+					}
+					boardReport.Tasks++
+					for _, label := range newTask.Labels {
+						boardReport.AddLabel(label.Title)
+					}
+					journalCard = &migration.JournalCard{
+						ShortLink: task.TrelloCardShortLink,
+						BoardID:   board.TrelloBoardID,
+						TaskID:    newTask.ID,
+						BucketID:  bucket.ID,
+						Partial:   true,
+					}
+					journal.RecordCard(task.TrelloCardID, journalCard)
+					// Saved right away, so a rollback finds the task even
+					// when the run is killed.
+					err = journal.Save()
+					if err != nil {
+						return err
+					}
+				}
+				taskLogger := boardLogger.WithFields(logrus.Fields{logging.CardID: task.TrelloCardID, logging.TaskID: newTask.ID})
+				if resumed {
+					taskLogger.Infof("Resuming card %s, an earlier run migrated it partially", task.Title)
+				} else {
+					taskLogger.Debugf("Created task %s", newTask.Title)
+				}
+
+				for _, view := range board.Views {
+					err := client.UpdateTaskPosition(&models.TaskPosition{
+						TaskID:        newTask.ID,
+						ProjectViewID: view.ID,
+						Position:      position,
+					})
+					if err != nil {
+						return err
+					}
+				}
+
+				if len(task.Comments) > 0 {
+					taskLogger.Debugf("Uploading %d comments", len(task.Comments))
+				}
+				// add task comments, after those of an earlier run
+				for i, comment := range task.Comments {
+					comment.TaskID = newTask.ID
+					if i < len(journalCard.CommentIDs) {
+						comment.ID = journalCard.CommentIDs[i]
+						continue
+					}
+					err := client.AddTaskComment(comment)
+					if err != nil {
+						return err
+					}
+					journalCard.CommentIDs = append(journalCard.CommentIDs, comment.ID)
+					boardReport.Comments++
+				}
+
+				var attachmentBar *progress.Bar
+				if len(task.Attachments) > 0 {
+					taskLogger.Debugf("Uploading %d attachments", len(task.Attachments))
+					attachmentBar = bars.Add("attachments of "+task.Title, len(task.Attachments), progress.Attachments)
+				}
+				previousUploads := make(map[string]int)
+				for _, attachment := range journalCard.Attachments {
+					previousUploads[attachment.Name]++
+				}
+				for _, attachment := range task.Attachments {
+					attachmentBar.Increment()
+					if len(attachment.File.FileContent) == 0 {
+						taskLogger.Warnf("Skipped empty attachment %s", attachment.File.Name)
+						boardReport.Skip("attachment", attachment.File.Name, fmt.Sprintf("the file of card %s is empty", task.TrelloCardShortLink))
+						continue
+					}
+					if previousUploads[attachment.File.Name] > 0 {
+						previousUploads[attachment.File.Name]--
+						continue
+					}
+					uploaded := migration.NewJournalAttachment(attachment.File.Name, attachment.File.FileContent)
+					err = client.AddTaskAttachments(newTask.ID, attachment)
+					if err != nil {
+						return err
+					}
+					journalCard.Attachments = append(journalCard.Attachments, uploaded)
+					boardReport.Attachments++
+					boardReport.BytesUploaded += uploaded.Size
+				}
+
+				if len(task.Subtasks) > 0 {
+					taskLogger.Debugf("Uploading %d subtasks", len(task.Subtasks))
+				}
+				for i, subtask := range task.Subtasks {
+					newSubtask := &subtask.Task
+					newSubtask.BucketID = bucket.ID
+					if i < len(journalCard.SubtaskIDs) {
+						newSubtask.ID = journalCard.SubtaskIDs[i]
+						continue
+					}
+
+					position := newSubtask.Position
+					err := client.AddTask(newSubtask)
+					if err != nil {
+						return err
+					}
+					for _, view := range board.Views {
+						err := client.UpdateTaskPosition(&models.TaskPosition{
+							TaskID:        newSubtask.ID,
+							ProjectViewID: view.ID,
+							Position:      position,
+						})
+						if err != nil {
+							return err
+						}
+					}
+
+					err = client.CreateTaskRelation(&models.TaskRelation{
+						TaskID:       newTask.ID,
+						OtherTaskID:  newSubtask.ID,
+						RelationKind: models.RelationKindSubtask,
+					})
+					if err != nil {
+						return err
+					}
+					journalCard.SubtaskIDs = append(journalCard.SubtaskIDs, newSubtask.ID)
+					boardReport.Subtasks++
+				}
+
+				journalCard.Partial = false
+				err := journal.Save()
+				if err != nil {
+					return err
+				}
+				taskBar.Increment()
+			}
+
+		}
+
+		// Also records boards without any bucket.
+		journal.RecordBoard(board.TrelloBoardID, board.TrelloBoardShortLink, board.ID, nil)
+		err := journal.Save()
+		if err != nil {
+			return err
+		}
+		boardReport.Finish()
+		boardLogger.WithField(logging.Duration, boardReport.Elapsed.String()).Infof("Uploaded %d tasks of board %s", boardReport.Tasks, board.Title)
+		taskBar.Done()
+		boardBar.Increment()
+	}
+
+	return nil
+}
+
+// scopedTokenLifetime is how long the api token of -vikunja-scoped-token is
+// valid when the migration doesn't get to delete it.
+const scopedTokenLifetime = 7 * 24 * time.Hour
+
+// newVikunjaClient creates a client for the instance of cfg, which logs in
+// with the username and password when no api key is set.
+func newVikunjaClient(cfg *config.Config) (*vikunja.Client, error) {
+	client := vikunja.NewClient(cfg.Vikunja.APIKey, cfg.Vikunja.Instance)
+	client.Logger = logger
+	if cfg.Vikunja.APIKey != "" || cfg.Vikunja.Username == "" {
+		return client, nil
+	}
+
+	err := client.Login(cfg.Vikunja.Username, cfg.Vikunja.Password, cfg.Vikunja.TOTP)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("Logged in to Vikunja as %s", cfg.Vikunja.Username)
+
+	return client, nil
+}
+
+// useMigrationToken switches client from its login to a new api token with
+// only the permissions the migration needs. release deletes the token with
+// the login again.
+func useMigrationToken(cfg *config.Config, client *vikunja.Client) (release func(), err error) {
+	if cfg.Vikunja.APIKey != "" || cfg.Vikunja.Username == "" {
+		return nil, fmt.Errorf("a scoped token is created from a login, set -vikunja-username and -vikunja-password instead of -vikunja-key")
+	}
+
+	token, err := client.CreateMigrationToken("trello-vikunja migration "+time.Now().Format(time.DateTime), scopedTokenLifetime)
+	if err != nil {
+		return nil, err
+	}
+	logger.WithField("token_id", token.ID).Infof("Created the api token %s", token.Title)
+
+	login := client.WithContext(context.Background())
+	client.UseToken(token.Token)
+
+	return func() {
+		if err := login.DeleteAPIToken(token.ID); err != nil {
+			logger.WithError(err).WithField("token_id", token.ID).Warnf("Could not delete the api token %s, delete it in the Vikunja settings", token.Title)
+			return
+		}
+		logger.WithField("token_id", token.ID).Infof("Deleted the api token %s", token.Title)
+	}, nil
+}
+
+// detectVikunja reads the version and limits of the instance of client and
+// fails when it lacks any of features.
+func detectVikunja(client *vikunja.Client, features ...vikunja.Feature) (*vikunja.Info, error) {
+	info, err := client.Detect()
+	if err != nil {
+		return nil, err
+	}
+	logger.WithField("vikunja_version", info.Version).Infof("Connected to Vikunja %s at %s", info.Version, client.BaseURL)
+
+	return info, client.Require(features...)
+}
+
+// planFeatures returns the features of Vikunja plan needs besides
+// projects.
+func planFeatures(plan *trello2vikunja.Plan) []vikunja.Feature {
+	var features []vikunja.Feature
+	attachments, comments := false, false
+	for _, summary := range plan.Summary() {
+		attachments = attachments || summary.Attachments > 0
+		comments = comments || summary.Comments > 0
+	}
+	if attachments {
+		features = append(features, vikunja.FeatureAttachments)
+	}
+	if comments {
+		features = append(features, vikunja.FeatureComments)
+	}
+
+	return features
+}
+
+// openTrelloSource opens the boards exported from the Trello UI when any are
+// configured, and the export of the exporter otherwise.
+func openTrelloSource(cfg *config.Config) (trellosource.Source, error) {
+	attachments := trellosource.Attachments{
+		Dir:    cfg.Files.AttachmentsDir,
+		Key:    cfg.Trello.APIKey,
+		Token:  cfg.Trello.APIToken,
+		Client: migration.HTTPClient,
+		Logger: migration.Logger,
+	}
+
+	if len(cfg.Files.TrelloUIExports) > 0 {
+		return trellosource.OpenUIExports(cfg.Files.TrelloUIExports, attachments)
+	}
+
+	source, err := trellosource.OpenFile(cfg.Files.Trello, attachments)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", cfg.Files.Trello, err)
+	}
+
+	return source, nil
+}
+
+// readDataFile reads the projects of a Vikunja export, either the zip or
+// its data.json, by title.
+func readDataFile(filename string) (map[string]models.Project, error) {
+	result, err := vikunja.ReadExport(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	dataMap := make(map[string]models.Project, len(result))
+	for _, data := range result {
+		dataMap[data.Title] = data
+	}
+
+	return dataMap, nil
+}
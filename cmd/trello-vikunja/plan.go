@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+
+	"wingaru.me/trello-migrate/internal/config"
+	"wingaru.me/trello-migrate/pkg/vikunja"
+)
+
+// runPlan converts the chosen boards like migrate does and prints what would
+// be created, without changing anything in Vikunja or downloading
+// attachments. Attachment sizes are checked when the instance is set.
+func runPlan(cfg *config.Config) error {
+	var info *vikunja.Info
+	if cfg.Vikunja.Instance != "" {
+		client := vikunja.NewClient(cfg.Vikunja.APIKey, cfg.Vikunja.Instance)
+		client.Logger = logger
+		var err error
+		info, err = client.Detect()
+		if err != nil {
+			logger.WithError(err).Warn("Not checking attachment sizes")
+		}
+	}
+
+	plan, err := prepareMigration(cfg, nil, info, true)
+	if err != nil {
+		return err
+	}
+
+	for _, project := range plan.Summary() {
+		fmt.Printf("[Trello Migration] %s -> project %d\n", project.Title, project.ProjectID)
+		fmt.Printf("  buckets:     %d\n", project.Buckets)
+		fmt.Printf("  tasks:       %d\n", project.Tasks)
+		fmt.Printf("  comments:    %d\n", project.Comments)
+		fmt.Printf("  attachments: %d\n", project.Attachments)
+		fmt.Printf("  labels:      %d\n", project.Labels)
+		fmt.Printf("  subtasks:    %d\n", project.Subtasks)
+		fmt.Printf("  card links:  %d\n", project.CardLinks)
+	}
+
+	if len(plan.Oversized) > 0 {
+		fmt.Printf("[Trello Migration] %d attachments are larger than Vikunja accepts\n", len(plan.Oversized))
+		for _, attachment := range plan.Oversized {
+			fmt.Printf("  %s on card %s of board %s: %s\n", attachment.Name, attachment.Card, attachment.Board, attachment.Reason())
+		}
+	}
+	if len(plan.LinkedAttachments) > 0 {
+		fmt.Printf("[Trello Migration] %d attachments are linked because their files could not be read\n", len(plan.LinkedAttachments))
+		for _, attachment := range plan.LinkedAttachments {
+			fmt.Printf("  %s on card %s of board %s: %v\n", attachment.Name, attachment.Card, attachment.Board, attachment.Err)
+		}
+	}
+	if len(plan.DroppedCheckItems) > 0 {
+		fmt.Printf("[Trello Migration] %d checklist items lose their due date or member\n", len(plan.DroppedCheckItems))
+		for _, item := range plan.DroppedCheckItems {
+			fmt.Printf("  %s on card %s of board %s: %s\n", item.Name, item.Card, item.Board, item.Reason())
+		}
+	}
+
+	return nil
+}
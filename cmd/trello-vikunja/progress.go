@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+	"wingaru.me/trello-migrate/internal/progress"
+	"wingaru.me/trello-migrate/pkg/trello2vikunja"
+)
+
+// bars shows the progress of export and migrate. It is nil for the other
+// commands, which keep their plain output.
+var bars *progress.Progress
+
+// startProgress shows progress bars for requests limited to limit when
+// stdout is a terminal. The console then only shows info and more severe
+// entries of the log, above the bars. The log file still gets everything.
+func startProgress(limit rate.Limit) {
+	bars = progress.New(limit)
+	if !bars.Enabled() {
+		return
+	}
+
+	logger.SetConsole(bars.Output(), logrus.InfoLevel)
+}
+
+// converterProgress shows the progress of a conversion with bars.
+type converterProgress struct {
+	bars *progress.Progress
+}
+
+func (p converterProgress) Add(name string, total int, level trello2vikunja.ProgressLevel) trello2vikunja.ProgressBar {
+	// The levels are in the same order.
+	return p.bars.Add(name, total, progress.Level(level))
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+
+	"wingaru.me/trello-migrate/internal/migration"
+	"wingaru.me/trello-migrate/pkg/models"
+	"wingaru.me/trello-migrate/pkg/trello2vikunja"
+	"wingaru.me/trello-migrate/pkg/vikunja"
+)
+
+// relationKey identifies a relation between two tasks. Related tasks are
+// related both ways, so their key does not depend on the direction.
+func relationKey(kind models.RelationKind, taskID int64, otherTaskID int64) string {
+	if kind == models.RelationKindRelated && otherTaskID < taskID {
+		taskID, otherTaskID = otherTaskID, taskID
+	}
+
+	return fmt.Sprintf("%s:%d:%d", kind, taskID, otherTaskID)
+}
+
+// createCardLinkRelations turns the card link attachments of all uploaded
+// tasks into task relations of kind. Linked cards are looked up in the
+// journal, so cards migrated from other boards or in earlier runs are found
+// as well. Mirrored cards, linking to each other, only get one relation.
+// Links to cards which were not migrated are added to the description.
+// Relations and unresolved links are counted in the report of the board.
+func createCardLinkRelations(client *vikunja.Client, projects []*models.ProjectWithTasksAndBuckets, journal *migration.Journal, kind models.RelationKind, report *migration.Report) (created int, err error) {
+	seen := make(map[string]bool)
+
+	for _, project := range projects {
+		boardReport := report.Board(project.TrelloBoardID)
+		for _, bucket := range project.Buckets {
+			for _, task := range bucket.TasksWithComments {
+				var unresolved []*models.TrelloCardLink
+				for _, link := range task.TrelloCardLinks {
+					otherTaskID, found := journal.TaskForShortLink(link.ShortLink)
+					if !found {
+						unresolved = append(unresolved, link)
+						continue
+					}
+
+					key := relationKey(kind, task.ID, otherTaskID)
+					if otherTaskID == task.ID || seen[key] {
+						continue
+					}
+					seen[key] = true
+
+					err := client.CreateTaskRelation(&models.TaskRelation{
+						TaskID:       task.ID,
+						OtherTaskID:  otherTaskID,
+						RelationKind: kind,
+					})
+					if err != nil {
+						return created, err
+					}
+					created++
+					boardReport.Relations++
+				}
+
+				if len(unresolved) == 0 {
+					continue
+				}
+
+				for _, link := range unresolved {
+					task.Description += trello2vikunja.AttachmentLink(link.Name, link.URL)
+					boardReport.Skip("card link", link.URL, fmt.Sprintf("the linked card was not migrated, kept as a link on task %d", task.ID))
+				}
+				if err := client.UpdateTask(&task.Task); err != nil {
+					return created, err
+				}
+			}
+		}
+	}
+
+	return created, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"github.com/sirupsen/logrus"
+	"wingaru.me/trello-migrate/internal/config"
+	"wingaru.me/trello-migrate/internal/logging"
+	"wingaru.me/trello-migrate/internal/migration"
+	"wingaru.me/trello-migrate/pkg/models"
+	"wingaru.me/trello-migrate/pkg/vikunja"
+)
+
+// runRollback deletes all tasks and buckets recorded in the journal from
+// Vikunja and removes them from the journal. The projects themselves existed
+// before the migration and are kept. Things which are already gone are
+// skipped, so an interrupted rollback can be run again.
+func runRollback(cfg *config.Config) error {
+	journal, err := migration.OpenJournal(cfg.Files.Journal)
+	if err != nil {
+		return err
+	}
+
+	client, err := newVikunjaClient(cfg)
+	if err != nil {
+		return err
+	}
+	if _, err := detectVikunja(client, vikunja.FeatureProjects); err != nil {
+		return err
+	}
+
+	for cardID, card := range journal.Cards {
+		taskIDs := append([]int64{card.TaskID}, card.SubtaskIDs...)
+		for _, taskID := range taskIDs {
+			err := client.DeleteTask(taskID)
+			if err != nil && !vikunja.IsNotFound(err) {
+				return err
+			}
+		}
+		logger.WithFields(logrus.Fields{logging.CardID: cardID, logging.TaskID: card.TaskID}).Infof("Deleted task and %d subtasks", len(card.SubtaskIDs))
+
+		delete(journal.Cards, cardID)
+		if err := journal.Save(); err != nil {
+			return err
+		}
+	}
+
+	for boardID, board := range journal.Boards {
+		for _, bucket := range board.Buckets {
+			err := client.DeleteBucket(&models.Bucket{ID: bucket.ID, ProjectID: board.ProjectID, ProjectViewID: bucket.ViewID})
+			if err != nil && !vikunja.IsNotFound(err) {
+				return err
+			}
+		}
+		logger.WithFields(logrus.Fields{logging.BoardID: boardID, logging.ProjectID: board.ProjectID}).Infof("Deleted %d buckets", len(board.Buckets))
+
+		delete(journal.Boards, boardID)
+		if err := journal.Save(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
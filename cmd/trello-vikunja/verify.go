@@ -0,0 +1,349 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"wingaru.me/trello-migrate/internal/config"
+	"wingaru.me/trello-migrate/internal/migration"
+	"wingaru.me/trello-migrate/pkg/models"
+	"wingaru.me/trello-migrate/pkg/trello2vikunja"
+	"wingaru.me/trello-migrate/pkg/trellosource"
+	"wingaru.me/trello-migrate/pkg/vikunja"
+)
+
+// verifyReport is the result of comparing every migrated card with the task
+// it became.
+type verifyReport struct {
+	Passed bool          `json:"passed"`
+	Cards  []*verifyCard `json:"cards"`
+}
+
+type verifyCard struct {
+	CardID    string        `json:"card_id"`
+	ShortLink string        `json:"short_link,omitempty"`
+	Name      string        `json:"name,omitempty"`
+	TaskID    int64         `json:"task_id,omitempty"`
+	Passed    bool          `json:"passed"`
+	Checks    []verifyCheck `json:"checks"`
+}
+
+type verifyCheck struct {
+	Name     string `json:"name"`
+	Passed   bool   `json:"passed"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	// Why a check failed without anything to compare.
+	Reason string `json:"reason,omitempty"`
+}
+
+// check adds a check comparing expected with actual.
+func (c *verifyCard) check(name string, expected string, actual string) {
+	c.Checks = append(c.Checks, verifyCheck{
+		Name:     name,
+		Passed:   expected == actual,
+		Expected: expected,
+		Actual:   actual,
+	})
+}
+
+// fail adds a failed check without values to compare.
+func (c *verifyCard) fail(name string, reason string) {
+	c.Checks = append(c.Checks, verifyCheck{Name: name, Reason: reason})
+}
+
+// runVerify converts the journaled boards again and compares every card with
+// the task in Vikunja: title, description, comment count, labels, attachments
+// and bucket. It writes a json report and fails when anything differs.
+func runVerify(cfg *config.Config) error {
+	options, err := configure(cfg)
+	if err != nil {
+		return err
+	}
+
+	journal, err := migration.OpenJournal(cfg.Files.Journal)
+	if err != nil {
+		return err
+	}
+
+	vikunjaData, err := readDataFile(cfg.Files.Data)
+	if err != nil {
+		return err
+	}
+
+	client, err := newVikunjaClient(cfg)
+	if err != nil {
+		return err
+	}
+	migration.Logger = logger
+	info, err := detectVikunja(client, vikunja.FeatureProjects)
+	if err != nil {
+		return err
+	}
+	options.MaxAttachmentSize, err = info.MaxFileSizeBytes()
+	if err != nil {
+		return err
+	}
+
+	source, err := openTrelloSource(cfg)
+	if err != nil {
+		return err
+	}
+
+	trelloData, err := trellosource.Load(source)
+	if err != nil {
+		return err
+	}
+
+	options.Boards = nil
+	for _, board := range trelloData {
+		if _, migrated := journal.Boards[board.ID]; migrated {
+			options.Boards = append(options.Boards, board.Name)
+		}
+	}
+
+	var projects []*models.ProjectWithTasksAndBuckets
+	if len(options.Boards) > 0 {
+		plan, err := convertBoards(client, source, trelloData, vikunjaData, options)
+		if err != nil {
+			return err
+		}
+		projects = plan.Projects
+	}
+
+	frontendURL := resolveFrontendURL(cfg, info)
+
+	expected := make(map[string]*models.TaskWithComments)
+	for _, project := range projects {
+		for _, bucket := range project.Buckets {
+			for _, task := range bucket.TasksWithComments {
+				expected[task.TrelloCardID] = task
+			}
+		}
+	}
+
+	taskBuckets, err := getTaskBuckets(client, journal)
+	if err != nil {
+		return err
+	}
+
+	cardIDs := make([]string, 0, len(expected))
+	for cardID := range expected {
+		cardIDs = append(cardIDs, cardID)
+	}
+	for cardID, card := range journal.Cards {
+		if _, exported := expected[cardID]; !exported && journal.Boards[card.BoardID] != nil {
+			cardIDs = append(cardIDs, cardID)
+		}
+	}
+	sort.Strings(cardIDs)
+
+	report := &verifyReport{Passed: true}
+	for _, cardID := range cardIDs {
+		card, err := verifyTask(client, cardID, expected[cardID], journal, frontendURL, taskBuckets)
+		if err != nil {
+			return err
+		}
+
+		report.Cards = append(report.Cards, card)
+		report.Passed = report.Passed && card.Passed
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(cfg.Files.VerifyReport, data, 0644)
+	if err != nil {
+		return err
+	}
+
+	failed := printVerifyReport(report)
+	fmt.Printf("[Trello Migration] Verified %d cards, %d failed. Report written to %s\n", len(report.Cards), failed, cfg.Files.VerifyReport)
+	if !report.Passed {
+		return fmt.Errorf("%d cards do not match their task", failed)
+	}
+
+	return nil
+}
+
+// getTaskBuckets maps every task in a journaled bucket to that bucket.
+func getTaskBuckets(client *vikunja.Client, journal *migration.Journal) (map[int64]int64, error) {
+	taskBuckets := make(map[int64]int64)
+	for _, board := range journal.Boards {
+		for _, bucket := range board.Buckets {
+			tasks, err := client.GetBucketTasks(board.ProjectID, bucket.ViewID, bucket.ID)
+			if vikunja.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			for _, task := range tasks {
+				taskBuckets[task.ID] = bucket.ID
+			}
+		}
+	}
+
+	return taskBuckets, nil
+}
+
+// verifyTask compares the card cardID, converted to expected, with the task
+// the journal says it became. expected is nil when the card is no longer in
+// the export.
+func verifyTask(client *vikunja.Client, cardID string, expected *models.TaskWithComments, journal *migration.Journal, frontendURL string, taskBuckets map[int64]int64) (*verifyCard, error) {
+	card := &verifyCard{CardID: cardID}
+	defer func() {
+		card.Passed = true
+		for _, check := range card.Checks {
+			card.Passed = card.Passed && check.Passed
+		}
+	}()
+
+	entry, migrated := journal.Cards[cardID]
+	if expected != nil {
+		card.ShortLink = expected.TrelloCardShortLink
+		card.Name = expected.Title
+	}
+	if !migrated {
+		card.fail("migrated", "not in the journal")
+		return card, nil
+	}
+	card.ShortLink = entry.ShortLink
+	card.TaskID = entry.TaskID
+	if expected == nil {
+		card.fail("exported", "not in the export")
+		return card, nil
+	}
+
+	task, err := client.GetTask(entry.TaskID)
+	if vikunja.IsNotFound(err) {
+		card.fail("exists", "task not found")
+		return card, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	card.check("title", expected.Title, task.Title)
+
+	description := expected.Description
+	for _, link := range expected.TrelloCardLinks {
+		if _, found := journal.TaskForShortLink(link.ShortLink); !found {
+			description += trello2vikunja.AttachmentLink(link.Name, link.URL)
+		}
+	}
+	card.check("description", normalizeDescription(description, journal, frontendURL), normalizeDescription(task.Description, journal, frontendURL))
+
+	comments, err := client.GetTaskComments(entry.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	card.check("comments", fmt.Sprint(len(expected.Comments)), fmt.Sprint(len(comments)))
+
+	card.check("labels", labelSet(expected.Labels), labelSet(task.Labels))
+
+	attachments, err := client.GetTaskAttachments(entry.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	actualAttachments := make([]migration.JournalAttachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		content, err := client.DownloadTaskAttachment(entry.TaskID, attachment.ID)
+		if err != nil {
+			return nil, err
+		}
+		actualAttachments = append(actualAttachments, migration.NewJournalAttachment(attachment.File.Name, content))
+	}
+	var expectedAttachments []migration.JournalAttachment
+	for _, attachment := range expected.Attachments {
+		// migrate skips empty files.
+		if len(attachment.File.FileContent) > 0 {
+			expectedAttachments = append(expectedAttachments, migration.NewJournalAttachment(attachment.File.Name, attachment.File.FileContent))
+		}
+	}
+	card.check("attachments", describeFiles(expectedAttachments), describeFiles(actualAttachments))
+	// The files migrate uploaded, which differ from the export when the
+	// Trello attachments changed since. Journals written before the files
+	// were recorded cannot be checked.
+	if len(entry.Attachments) > 0 {
+		card.check("uploaded attachments", describeFiles(entry.Attachments), describeFiles(actualAttachments))
+	}
+
+	// Journals written before buckets were recorded per card cannot be
+	// checked.
+	if entry.BucketID != 0 {
+		card.check("bucket", fmt.Sprint(entry.BucketID), fmt.Sprint(taskBuckets[entry.TaskID]))
+	}
+
+	return card, nil
+}
+
+// normalizeDescription points Trello links at Vikunja the way the migration
+// does after uploading, so descriptions compare equal whether or not a link
+// was rewritten already.
+func normalizeDescription(description string, journal *migration.Journal, frontendURL string) string {
+	description, _ = rewriteTrelloLinks(strings.TrimSpace(description), journal, frontendURL)
+	return description
+}
+
+func labelSet(labels []*models.Label) string {
+	titles := make([]string, 0, len(labels))
+	for _, label := range labels {
+		titles = append(titles, label.Title)
+	}
+	sort.Strings(titles)
+
+	return strings.Join(titles, ", ")
+}
+
+// describeFiles lists the name, size and checksum of files, sorted.
+func describeFiles(files []migration.JournalAttachment) string {
+	described := make([]string, 0, len(files))
+	for _, file := range files {
+		described = append(described, fmt.Sprintf("%s (%d bytes, sha256 %s)", file.Name, file.Size, file.SHA256))
+	}
+	sort.Strings(described)
+
+	return strings.Join(described, "\n")
+}
+
+// printVerifyReport prints the failed checks of all cards and returns the
+// number of failed cards.
+func printVerifyReport(report *verifyReport) (failed int) {
+	for _, card := range report.Cards {
+		if card.Passed {
+			continue
+		}
+		failed++
+
+		fmt.Printf("[Trello Migration] FAIL card %s %q (task %d)\n", card.CardID, card.Name, card.TaskID)
+		for _, check := range card.Checks {
+			if check.Passed {
+				continue
+			}
+			if check.Reason != "" {
+				fmt.Printf("    %s: %s\n", check.Name, check.Reason)
+				continue
+			}
+			fmt.Printf("    %s: expected %q, got %q\n", check.Name, abbreviate(check.Expected), abbreviate(check.Actual))
+		}
+	}
+
+	return failed
+}
+
+// abbreviate shortens long values, like descriptions, for the terminal. The
+// json report has them in full.
+func abbreviate(value string) string {
+	const maxLength = 80
+	if len(value) <= maxLength {
+		return value
+	}
+
+	return value[:maxLength] + "..."
+}
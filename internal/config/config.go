@@ -0,0 +1,246 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds everything the exporter and migrator can be configured with.
+// Values are read from a yaml or toml file first and can then be overridden
+// by environment variables and command line flags.
+type Config struct {
+	Trello  Trello  `yaml:"trello" toml:"trello"`
+	Vikunja Vikunja `yaml:"vikunja" toml:"vikunja"`
+	Files   Files   `yaml:"files" toml:"files"`
+	Export  Export  `yaml:"export" toml:"export"`
+	Migrate Migrate `yaml:"migrate" toml:"migrate"`
+	Log     Log     `yaml:"log" toml:"log"`
+
+	// File is the config file the configuration was read from, empty when
+	// there is none.
+	File string `yaml:"-" toml:"-"`
+}
+
+type Trello struct {
+	APIKey   string `yaml:"api_key" toml:"api_key"`
+	APIToken string `yaml:"api_token" toml:"api_token"`
+	// How long tokens from "auth trello" are valid: 1hour, 1day, 30days or
+	// never.
+	TokenExpiration string `yaml:"token_expiration" toml:"token_expiration"`
+	// The local port Trello sends the token of "auth trello" to. The api
+	// key must allow http://localhost on this port as origin.
+	CallbackPort int `yaml:"callback_port" toml:"callback_port"`
+}
+
+type Vikunja struct {
+	// The url of the api, like https://vikunja.tld/api/v1
+	Instance string `yaml:"instance" toml:"instance"`
+	APIKey   string `yaml:"api_key" toml:"api_key"`
+	// The url of the frontend, used for links to migrated tasks. Derived from
+	// Instance when empty.
+	FrontendURL string `yaml:"frontend_url" toml:"frontend_url"`
+	// A login used when APIKey is empty. TOTP is the current passcode for
+	// users with two factor authentication.
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+	TOTP     string `yaml:"totp" toml:"totp"`
+	// Makes migrate create an api token with only the permissions it needs
+	// from the login, and delete it at the end.
+	ScopedToken bool `yaml:"scoped_token" toml:"scoped_token"`
+}
+
+type Files struct {
+	// The export written by the exporter and read by the migrator.
+	Trello string `yaml:"trello" toml:"trello"`
+	// Boards exported from the Trello UI, read instead of Trello when set.
+	TrelloUIExports []string `yaml:"trello_ui_exports" toml:"trello_ui_exports"`
+	// The files of uploaded attachments saved by the exporter, read by the
+	// migrator before downloading them from Trello. Empty disables it.
+	AttachmentsDir string `yaml:"attachments_dir" toml:"attachments_dir"`
+	// The watermarks of incremental exports.
+	ExportState string `yaml:"export_state" toml:"export_state"`
+	// The Vikunja export used to map boards to projects.
+	Data string `yaml:"data" toml:"data"`
+	// The record of everything migrated so far.
+	Journal string `yaml:"journal" toml:"journal"`
+	// The reports written by migrate. Empty disables a format.
+	ReportMarkdown string `yaml:"report_markdown" toml:"report_markdown"`
+	ReportJSON     string `yaml:"report_json" toml:"report_json"`
+	// A Vikunja import archive written by migrate instead of uploading.
+	OutputArchive string `yaml:"output_archive" toml:"output_archive"`
+	// The json report written by verify.
+	VerifyReport string `yaml:"verify_report" toml:"verify_report"`
+}
+
+type Export struct {
+	History     bool `yaml:"history" toml:"history"`
+	Incremental bool `yaml:"incremental" toml:"incremental"`
+}
+
+type Migrate struct {
+	// Names of the boards to migrate. When empty, the migrator asks.
+	Boards []string `yaml:"boards" toml:"boards"`
+	// Either size, for buckets of up to 200 tasks, or list, for a bucket
+	// per Trello list.
+	BucketStrategy     string `yaml:"bucket_strategy" toml:"bucket_strategy"`
+	HistoryMode        string `yaml:"history_mode" toml:"history_mode"`
+	ChecklistMode      string `yaml:"checklist_mode" toml:"checklist_mode"`
+	CardLinkRelation   string `yaml:"card_link_relation" toml:"card_link_relation"`
+	CustomFieldsConfig string `yaml:"custom_fields_config" toml:"custom_fields_config"`
+	HTMLAllowlist      string `yaml:"html_allowlist" toml:"html_allowlist"`
+	ColorPalette       string `yaml:"color_palette" toml:"color_palette"`
+	// What happens to attachments larger than Vikunja accepts: link, zip
+	// or fail.
+	OversizePolicy string `yaml:"oversize_policy" toml:"oversize_policy"`
+}
+
+type Log struct {
+	// One of trace, debug, info, warning, error.
+	Level string `yaml:"level" toml:"level"`
+	// Either text or json.
+	Format string `yaml:"format" toml:"format"`
+	// A file every entry is appended to, besides the console.
+	File string `yaml:"file" toml:"file"`
+}
+
+// Default returns the configuration used when nothing else is set.
+func Default() *Config {
+	return &Config{
+		Trello: Trello{
+			TokenExpiration: "30days",
+			CallbackPort:    8089,
+		},
+		Files: Files{
+			Trello:         "trello.json",
+			ExportState:    "trello.state.json",
+			Data:           "data.json",
+			Journal:        "journal.json",
+			ReportMarkdown: "migration-report.md",
+			ReportJSON:     "migration-report.json",
+			VerifyReport:   "verify.json",
+		},
+		Migrate: Migrate{
+			CardLinkRelation: "related",
+		},
+		Log: Log{
+			Level:  "info",
+			Format: "text",
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the config file at
+// filename (if not empty) and the environment, in that order. A .env file in
+// the working directory is loaded into the environment when it exists.
+func Load(filename string) (*Config, error) {
+	cfg := Default()
+
+	if filename != "" {
+		if err := cfg.readFile(filename); err != nil {
+			return nil, err
+		}
+		cfg.File = filename
+	}
+
+	err := godotenv.Load(EnvFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	cfg.applyEnv()
+
+	return cfg, nil
+}
+
+func (cfg *Config) readFile(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".toml":
+		return toml.Unmarshal(data, cfg)
+	default:
+		return yaml.Unmarshal(data, cfg)
+	}
+}
+
+// applyEnv overrides the configuration with all environment variables which
+// are set.
+func (cfg *Config) applyEnv() {
+	stringVars := map[string]*string{
+		"TRELLO_API_KEY":              &cfg.Trello.APIKey,
+		"TRELLO_API_TOKEN":            &cfg.Trello.APIToken,
+		"TRELLO_TOKEN_EXPIRATION":     &cfg.Trello.TokenExpiration,
+		"VIKUNJA_INSTANCE":            &cfg.Vikunja.Instance,
+		"VIKUNJA_API_KEY":             &cfg.Vikunja.APIKey,
+		"VIKUNJA_FRONTEND_URL":        &cfg.Vikunja.FrontendURL,
+		"VIKUNJA_USERNAME":            &cfg.Vikunja.Username,
+		"VIKUNJA_PASSWORD":            &cfg.Vikunja.Password,
+		"VIKUNJA_TOTP":                &cfg.Vikunja.TOTP,
+		"TRELLO_FILE":                 &cfg.Files.Trello,
+		"TRELLO_EXPORT_STATE":         &cfg.Files.ExportState,
+		"TRELLO_ATTACHMENTS_DIR":      &cfg.Files.AttachmentsDir,
+		"VIKUNJA_DATA_FILE":           &cfg.Files.Data,
+		"MIGRATION_JOURNAL":           &cfg.Files.Journal,
+		"VERIFY_REPORT":               &cfg.Files.VerifyReport,
+		"OUTPUT_ARCHIVE":              &cfg.Files.OutputArchive,
+		"MIGRATION_REPORT_MARKDOWN":   &cfg.Files.ReportMarkdown,
+		"MIGRATION_REPORT_JSON":       &cfg.Files.ReportJSON,
+		"TRELLO_HISTORY_MODE":         &cfg.Migrate.HistoryMode,
+		"TRELLO_CHECKLIST_MODE":       &cfg.Migrate.ChecklistMode,
+		"TRELLO_BUCKET_STRATEGY":      &cfg.Migrate.BucketStrategy,
+		"TRELLO_OVERSIZE_POLICY":      &cfg.Migrate.OversizePolicy,
+		"TRELLO_CARD_LINK_RELATION":   &cfg.Migrate.CardLinkRelation,
+		"TRELLO_CUSTOM_FIELDS_CONFIG": &cfg.Migrate.CustomFieldsConfig,
+		"TRELLO_HTML_ALLOWLIST":       &cfg.Migrate.HTMLAllowlist,
+		"TRELLO_COLOR_PALETTE":        &cfg.Migrate.ColorPalette,
+		"LOG_LEVEL":                   &cfg.Log.Level,
+		"LOG_FORMAT":                  &cfg.Log.Format,
+		"LOG_FILE":                    &cfg.Log.File,
+	}
+	for name, target := range stringVars {
+		if value, set := os.LookupEnv(name); set && value != "" {
+			*target = value
+		}
+	}
+
+	boolVars := map[string]*bool{
+		"TRELLO_EXPORT_HISTORY":     &cfg.Export.History,
+		"TRELLO_EXPORT_INCREMENTAL": &cfg.Export.Incremental,
+		"VIKUNJA_SCOPED_TOKEN":      &cfg.Vikunja.ScopedToken,
+	}
+	for name, target := range boolVars {
+		if value, err := strconv.ParseBool(os.Getenv(name)); err == nil {
+			*target = value
+		}
+	}
+
+	if value := os.Getenv("TRELLO_BOARDS"); value != "" {
+		cfg.Migrate.Boards = SplitList(value)
+	}
+	if value := os.Getenv("TRELLO_UI_EXPORTS"); value != "" {
+		cfg.Files.TrelloUIExports = SplitList(value)
+	}
+}
+
+// SplitList splits a comma separated list and trims its items.
+func SplitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
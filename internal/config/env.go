@@ -0,0 +1,65 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+)
+
+// EnvFile is the .env file Load reads from the working directory.
+const EnvFile = ".env"
+
+// SetEnv sets variables in the .env file filename, replacing their lines
+// and appending the ones which aren't set yet. Everything else in the file
+// is kept. The file is created when it doesn't exist.
+func SetEnv(filename string, values map[string]string) error {
+	content, err := os.ReadFile(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	var lines []string
+	if len(content) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	}
+
+	written := make(map[string]bool, len(values))
+	for i, line := range lines {
+		name, _, found := strings.Cut(strings.TrimPrefix(strings.TrimSpace(line), "export "), "=")
+		value, set := values[strings.TrimSpace(name)]
+		if !found || !set {
+			continue
+		}
+		lines[i] = strings.TrimSpace(name) + "=" + quoteEnv(value)
+		written[strings.TrimSpace(name)] = true
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		if !written[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lines = append(lines, name+"="+quoteEnv(values[name]))
+	}
+
+	return os.WriteFile(filename, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+}
+
+// quoteEnv quotes value when godotenv would read it differently otherwise.
+// Single quotes keep everything as is, double quotes are only needed for
+// values with a single quote.
+func quoteEnv(value string) string {
+	if !strings.ContainsAny(value, " \t#\"'\\$") {
+		return value
+	}
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`).Replace(value) + `"`
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joho/godotenv"
+)
+
+func TestQuoteEnv(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"0123abcdef", "0123abcdef"},
+		{"ATTA$token#1", `'ATTA$token#1'`},
+		{"two words", `'two words'`},
+		{`say "hi"`, `'say "hi"'`},
+		{`back\slash`, `'back\slash'`},
+		{"it's $5", `"it's \$5"`},
+		{`it's "C:\" here`, `"it's \"C:\\\" here"`},
+	}
+	for _, test := range tests {
+		if got := quoteEnv(test.value); got != test.want {
+			t.Errorf("quoteEnv(%q) = %s, want %s", test.value, got, test.want)
+		}
+
+		// godotenv reads the value back unchanged.
+		read, err := godotenv.Unmarshal("VALUE=" + quoteEnv(test.value))
+		if err != nil {
+			t.Errorf("reading %s: %v", quoteEnv(test.value), err)
+			continue
+		}
+		if read["VALUE"] != test.value {
+			t.Errorf("godotenv reads %s as %q, want %q", quoteEnv(test.value), read["VALUE"], test.value)
+		}
+	}
+}
+
+func TestSetEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		values   map[string]string
+		want     string
+	}{
+		{
+			name:   "new file",
+			values: map[string]string{"TRELLO_API_TOKEN": "ATTA$token#1", "TRELLO_API_KEY": "key"},
+			want:   "TRELLO_API_KEY=key\nTRELLO_API_TOKEN='ATTA$token#1'\n",
+		},
+		{
+			name:     "replaces and appends",
+			existing: "# Trello\nexport TRELLO_API_KEY=old\nVIKUNJA_API_KEY=kept\n",
+			values:   map[string]string{"TRELLO_API_KEY": "key", "TRELLO_API_TOKEN": "token"},
+			want:     "# Trello\nTRELLO_API_KEY=key\nVIKUNJA_API_KEY=kept\nTRELLO_API_TOKEN=token\n",
+		},
+		{
+			name:     "without final newline",
+			existing: "TRELLO_API_TOKEN = old",
+			values:   map[string]string{"TRELLO_API_TOKEN": "new"},
+			want:     "TRELLO_API_TOKEN=new\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), ".env")
+			if test.existing != "" {
+				if err := os.WriteFile(filename, []byte(test.existing), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := SetEnv(filename, test.values); err != nil {
+				t.Fatalf("SetEnv: %v", err)
+			}
+
+			content, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != test.want {
+				t.Errorf("the file is\n%s\nwant\n%s", content, test.want)
+			}
+
+			read, err := godotenv.Read(filename)
+			if err != nil {
+				t.Fatalf("reading the file: %v", err)
+			}
+			for name, value := range test.values {
+				if read[name] != value {
+					t.Errorf("godotenv reads %s as %q, want %q", name, read[name], value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// SetFile sets keys of section in the yaml or toml config file filename,
+// replacing their values and adding the ones which aren't set yet. Comments
+// and everything else in the file are kept.
+func SetFile(filename string, section string, values map[string]string) error {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".toml":
+		content, err = setTOML(content, section, values)
+	default:
+		content, err = setYAML(content, section, values)
+	}
+	if err != nil {
+		return fmt.Errorf("updating %s: %w", filename, err)
+	}
+
+	return os.WriteFile(filename, content, 0600)
+}
+
+// setYAML sets values in the mapping section of a yaml document.
+func setYAML(content []byte, section string, values map[string]string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("the config is not a mapping")
+	}
+
+	table := yamlValue(root, section)
+	if table == nil {
+		table = &yaml.Node{Kind: yaml.MappingNode}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: section}, table)
+	}
+	if table.Kind != yaml.MappingNode {
+		// An empty section, like "trello:" without keys, is null.
+		if table.Tag != "!!null" {
+			return nil, fmt.Errorf("%s is not a mapping", section)
+		}
+		*table = yaml.Node{Kind: yaml.MappingNode, HeadComment: table.HeadComment, LineComment: table.LineComment}
+	}
+
+	for _, key := range sortedKeys(values) {
+		value := yamlValue(table, key)
+		if value == nil {
+			value = &yaml.Node{}
+			table.Content = append(table.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+		}
+		*value = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: values[key], LineComment: value.LineComment}
+	}
+
+	buf := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// yamlValue returns the value of key in mapping, or nil if it has none.
+func yamlValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// setTOML sets values in the table section, line by line like SetEnv, so
+// the comments survive. Keys of the table are expected as plain key = value
+// lines.
+func setTOML(content []byte, section string, values map[string]string) ([]byte, error) {
+	var lines []string
+	if len(content) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	}
+
+	// The table runs from its header to the next header.
+	start, end := -1, len(lines)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "[") {
+			continue
+		}
+		if start >= 0 {
+			end = i
+			break
+		}
+		header, _, _ := strings.Cut(trimmed, "#")
+		if strings.TrimSpace(header) == "["+section+"]" {
+			start = i
+		}
+	}
+
+	written := make(map[string]bool, len(values))
+	for i := start + 1; start >= 0 && i < end; i++ {
+		key, _, found := strings.Cut(lines[i], "=")
+		key = strings.TrimSpace(key)
+		if _, set := values[key]; found && set && !strings.HasPrefix(key, "#") {
+			lines[i] = key + " = " + quoteTOML(values[key])
+			written[key] = true
+		}
+	}
+
+	var added []string
+	for _, key := range sortedKeys(values) {
+		if !written[key] {
+			added = append(added, key+" = "+quoteTOML(values[key]))
+		}
+	}
+	if start < 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+section+"]")
+		lines = append(lines, added...)
+	} else {
+		// After the last key of the table, before the blank lines.
+		at := end
+		for at > start+1 && strings.TrimSpace(lines[at-1]) == "" {
+			at--
+		}
+		lines = append(lines[:at], append(added, lines[at:]...)...)
+	}
+
+	updated := []byte(strings.Join(lines, "\n") + "\n")
+	// Better to fail than to write a config which can't be read.
+	if err := toml.Unmarshal(updated, &map[string]interface{}{}); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+var tomlEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// quoteTOML writes value as a toml basic string.
+func quoteTOML(value string) string {
+	return `"` + tomlEscaper.Replace(value) + `"`
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
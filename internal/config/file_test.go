@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		existing string
+		// keep are lines of the existing file which must survive.
+		keep []string
+	}{
+		{
+			name:     "yaml with the section",
+			file:     "config.yaml",
+			existing: "# migration settings\ntrello:\n  api_key: old # from the power-up\n  api_token: old\n  token_expiration: 1day\nfiles:\n  trello: boards.json\n",
+			keep:     []string{"# migration settings", "# from the power-up", "token_expiration: 1day", "trello: boards.json"},
+		},
+		{
+			name:     "yaml without the section",
+			file:     "config.yml",
+			existing: "files:\n  trello: boards.json\n",
+			keep:     []string{"trello: boards.json"},
+		},
+		{
+			name:     "yaml with an empty section",
+			file:     "config.yaml",
+			existing: "trello:\nfiles:\n  trello: boards.json\n",
+			keep:     []string{"trello: boards.json"},
+		},
+		{
+			name: "empty yaml",
+			file: "config.yaml",
+		},
+		{
+			name:     "toml with the section",
+			file:     "config.toml",
+			existing: "# migration settings\n[trello]\napi_key = \"old\" # from the power-up\ntoken_expiration = \"1day\"\n\n[files]\ntrello = \"boards.json\"\n",
+			keep:     []string{"# migration settings", `token_expiration = "1day"`, "[files]", `trello = "boards.json"`},
+		},
+		{
+			name:     "toml without the section",
+			file:     "config.toml",
+			existing: "[files]\ntrello = \"boards.json\"\n",
+			keep:     []string{"[files]", `trello = "boards.json"`},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), test.file)
+			if err := os.WriteFile(filename, []byte(test.existing), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			// The token looks like a number and has characters yaml and toml
+			// have to quote.
+			values := map[string]string{"api_key": "key", "api_token": `12345 "$#\`}
+			if err := SetFile(filename, "trello", values); err != nil {
+				t.Fatalf("SetFile: %v", err)
+			}
+
+			content, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, line := range test.keep {
+				if !strings.Contains(string(content), line) {
+					t.Errorf("the config lost %q:\n%s", line, content)
+				}
+			}
+
+			cfg := Default()
+			if err := cfg.readFile(filename); err != nil {
+				t.Fatalf("reading the updated config: %v\n%s", err, content)
+			}
+			if cfg.Trello.APIKey != values["api_key"] || cfg.Trello.APIToken != values["api_token"] {
+				t.Errorf("the config has the key %q and token %q, want %q and %q:\n%s", cfg.Trello.APIKey, cfg.Trello.APIToken, values["api_key"], values["api_token"], content)
+			}
+			if strings.Contains(test.existing, "boards.json") && cfg.Files.Trello != "boards.json" {
+				t.Errorf("the config lost the other sections:\n%s", content)
+			}
+		})
+	}
+}
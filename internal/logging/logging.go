@@ -0,0 +1,117 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"wingaru.me/trello-migrate/internal/config"
+)
+
+// Names of the fields shared by all log entries about the same things.
+const (
+	BoardID    = "board_id"
+	CardID     = "card_id"
+	TaskID     = "task_id"
+	ProjectID  = "project_id"
+	HTTPStatus = "http_status"
+	Duration   = "duration"
+)
+
+// Logger is the logger used by all commands. Entries go to the console and,
+// if configured, to a log file, each with its own level.
+type Logger struct {
+	*logrus.Logger
+
+	console *writerHook
+	file    *os.File
+}
+
+// New creates a Logger from cfg. Entries are written to stderr until
+// SetConsole says otherwise.
+func New(cfg config.Log) (*Logger, error) {
+	level, err := logrus.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	var formatter logrus.Formatter
+	switch cfg.Format {
+	case "json":
+		formatter = &logrus.JSONFormatter{}
+	case "text", "":
+		formatter = &logrus.TextFormatter{FullTimestamp: true}
+	default:
+		return nil, fmt.Errorf("unknown log format %q, use json or text", cfg.Format)
+	}
+
+	logger := &Logger{
+		Logger:  logrus.New(),
+		console: &writerHook{writer: os.Stderr, formatter: formatter, level: level},
+	}
+	logger.SetLevel(level)
+	logger.SetOutput(io.Discard)
+	logger.AddHook(logger.console)
+
+	if cfg.File != "" {
+		logger.file, err = os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		logger.AddHook(&writerHook{writer: logger.file, formatter: formatter, level: level})
+	}
+
+	return logger, nil
+}
+
+// SetConsole writes the entries up to level to w instead of stderr. The log
+// file keeps getting all entries.
+func (l *Logger) SetConsole(w io.Writer, level logrus.Level) {
+	l.console.mu.Lock()
+	defer l.console.mu.Unlock()
+
+	l.console.writer = w
+	if level < l.console.level {
+		l.console.level = level
+	}
+}
+
+// Close closes the log file.
+func (l *Logger) Close() error {
+	if l.file == nil {
+		return nil
+	}
+
+	return l.file.Close()
+}
+
+// writerHook writes the entries up to level to writer.
+type writerHook struct {
+	mu        sync.Mutex
+	writer    io.Writer
+	formatter logrus.Formatter
+	level     logrus.Level
+}
+
+func (h *writerHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *writerHook) Fire(entry *logrus.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if entry.Level > h.level {
+		return nil
+	}
+
+	data, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	_, err = h.writer.Write(data)
+
+	return err
+}
@@ -0,0 +1,167 @@
+package migration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// Journal records which Vikunja entity every migrated Trello entity became.
+// It is kept on disk so later runs and post-migration passes can look up the
+// result of earlier ones.
+type Journal struct {
+	filename string
+
+	// Migrated boards, by Trello board id.
+	Boards map[string]*JournalBoard `json:"boards"`
+	// Migrated cards, by Trello card id.
+	Cards map[string]*JournalCard `json:"cards"`
+}
+
+// JournalBoard is a Trello board which was migrated into a Vikunja project.
+type JournalBoard struct {
+	ShortLink string `json:"short_link"`
+	ProjectID int64  `json:"project_id"`
+	// The buckets created in the project, by all runs.
+	Buckets []JournalBucket `json:"buckets,omitempty"`
+}
+
+// JournalBucket is a bucket created in the view ViewID of a project.
+type JournalBucket struct {
+	ID     int64 `json:"id"`
+	ViewID int64 `json:"view_id"`
+	// Empty in journals written before buckets were reused by title.
+	Title string `json:"title,omitempty"`
+}
+
+// JournalCard is a Trello card which was migrated into a Vikunja task.
+type JournalCard struct {
+	ShortLink string `json:"short_link"`
+	BoardID   string `json:"board_id"`
+	TaskID    int64  `json:"task_id"`
+	// The bucket the task was put into.
+	BucketID int64 `json:"bucket_id,omitempty"`
+	// Ids of the comments created on the task.
+	CommentIDs []int64 `json:"comment_ids,omitempty"`
+	// Ids of the tasks created from the checklist items of the card.
+	SubtaskIDs []int64 `json:"subtask_ids,omitempty"`
+	// The files uploaded to the task.
+	Attachments []JournalAttachment `json:"attachments,omitempty"`
+	// Partial is set while the comments, attachments and subtasks of the
+	// task are uploaded, and stays set when that failed.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// JournalAttachment is a file uploaded as attachment of a task.
+type JournalAttachment struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// NewJournalAttachment describes the file name with content.
+func NewJournalAttachment(name string, content []byte) JournalAttachment {
+	sum := sha256.Sum256(content)
+	return JournalAttachment{Name: name, Size: int64(len(content)), SHA256: hex.EncodeToString(sum[:])}
+}
+
+// OpenJournal reads the journal from filename. A missing file results in an
+// empty journal which will be created on the first Save.
+func OpenJournal(filename string) (*Journal, error) {
+	journal := &Journal{
+		filename: filename,
+		Boards:   map[string]*JournalBoard{},
+		Cards:    map[string]*JournalCard{},
+	}
+
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return journal, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, journal); err != nil {
+		return nil, err
+	}
+	if journal.Boards == nil {
+		journal.Boards = map[string]*JournalBoard{}
+	}
+	if journal.Cards == nil {
+		journal.Cards = map[string]*JournalCard{}
+	}
+
+	return journal, nil
+}
+
+// Save writes the journal back to its file.
+func (j *Journal) Save() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(j.filename, data, 0644)
+}
+
+// RecordBoard records that the Trello board boardID became project
+// projectID, with buckets created in it. Buckets of earlier runs are kept.
+func (j *Journal) RecordBoard(boardID string, shortLink string, projectID int64, buckets []JournalBucket) {
+	board, exists := j.Boards[boardID]
+	if !exists {
+		board = &JournalBoard{}
+		j.Boards[boardID] = board
+	}
+
+	board.ShortLink = shortLink
+	board.ProjectID = projectID
+	board.Buckets = append(board.Buckets, buckets...)
+}
+
+// BucketForTitle returns the bucket titled title an earlier run created in
+// the view viewID of the project of the board boardID.
+func (j *Journal) BucketForTitle(boardID string, viewID int64, title string) (int64, bool) {
+	board, exists := j.Boards[boardID]
+	if !exists || title == "" {
+		return 0, false
+	}
+
+	for _, bucket := range board.Buckets {
+		if bucket.ViewID == viewID && bucket.Title == title {
+			return bucket.ID, true
+		}
+	}
+
+	return 0, false
+}
+
+// RecordCard records that the Trello card cardID became the task in entry.
+func (j *Journal) RecordCard(cardID string, entry *JournalCard) {
+	j.Cards[cardID] = entry
+}
+
+// ProjectForShortLink returns the project a board with shortLink became.
+func (j *Journal) ProjectForShortLink(shortLink string) (int64, bool) {
+	for _, board := range j.Boards {
+		if board.ShortLink == shortLink {
+			return board.ProjectID, true
+		}
+	}
+
+	return 0, false
+}
+
+// TaskForShortLink returns the task a card with shortLink became.
+func (j *Journal) TaskForShortLink(shortLink string) (int64, bool) {
+	for _, card := range j.Cards {
+		if card.ShortLink == shortLink {
+			return card.TaskID, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,109 @@
+package migration
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestJournalSaveAndOpen(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "journal.json")
+
+	journal, err := OpenJournal(filename)
+	if err != nil {
+		t.Fatalf("opening a missing journal: %v", err)
+	}
+	if len(journal.Boards) != 0 || len(journal.Cards) != 0 {
+		t.Fatalf("a missing journal has %d boards and %d cards, want none", len(journal.Boards), len(journal.Cards))
+	}
+
+	journal.RecordBoard("board1", "bShort", 3, []JournalBucket{{ID: 10, ViewID: 4, Title: "To Do"}})
+	journal.RecordBoard("board1", "bShort", 3, []JournalBucket{{ID: 11, ViewID: 4, Title: "Done"}})
+	journal.RecordCard("card1", &JournalCard{
+		ShortLink:   "cShort",
+		BoardID:     "board1",
+		TaskID:      20,
+		BucketID:    10,
+		CommentIDs:  []int64{30},
+		SubtaskIDs:  []int64{21},
+		Attachments: []JournalAttachment{NewJournalAttachment("notes.txt", []byte("hello"))},
+		Partial:     true,
+	})
+	if err := journal.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	read, err := OpenJournal(filename)
+	if err != nil {
+		t.Fatalf("opening the saved journal: %v", err)
+	}
+	if !reflect.DeepEqual(read.Boards, journal.Boards) {
+		t.Errorf("the boards read back are %+v, want %+v", read.Boards, journal.Boards)
+	}
+	if !reflect.DeepEqual(read.Cards, journal.Cards) {
+		t.Errorf("the cards read back are %+v, want %+v", read.Cards["card1"], journal.Cards["card1"])
+	}
+	if got := len(read.Boards["board1"].Buckets); got != 2 {
+		t.Errorf("the board has %d buckets, want the 2 of both runs", got)
+	}
+}
+
+func TestNewJournalAttachment(t *testing.T) {
+	attachment := NewJournalAttachment("notes.txt", []byte("hello"))
+	want := JournalAttachment{
+		Name:   "notes.txt",
+		Size:   5,
+		SHA256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+	}
+	if attachment != want {
+		t.Errorf("NewJournalAttachment = %+v, want %+v", attachment, want)
+	}
+}
+
+func TestJournalLookups(t *testing.T) {
+	journal := &Journal{
+		Boards: map[string]*JournalBoard{
+			"board1": {ShortLink: "bOne", ProjectID: 3, Buckets: []JournalBucket{
+				{ID: 10, ViewID: 4, Title: "To Do"},
+				{ID: 11, ViewID: 5, Title: "To Do"},
+				{ID: 12, ViewID: 4},
+			}},
+		},
+		Cards: map[string]*JournalCard{
+			"card1": {ShortLink: "cOne", BoardID: "board1", TaskID: 20},
+		},
+	}
+
+	buckets := []struct {
+		board  string
+		view   int64
+		title  string
+		want   int64
+		wantOk bool
+	}{
+		{"board1", 4, "To Do", 10, true},
+		{"board1", 5, "To Do", 11, true},
+		{"board1", 4, "Done", 0, false},
+		{"board1", 4, "", 0, false},
+		{"board2", 4, "To Do", 0, false},
+	}
+	for _, test := range buckets {
+		got, ok := journal.BucketForTitle(test.board, test.view, test.title)
+		if got != test.want || ok != test.wantOk {
+			t.Errorf("BucketForTitle(%q, %d, %q) = %d, %t, want %d, %t", test.board, test.view, test.title, got, ok, test.want, test.wantOk)
+		}
+	}
+
+	if id, ok := journal.ProjectForShortLink("bOne"); id != 3 || !ok {
+		t.Errorf("ProjectForShortLink(bOne) = %d, %t, want 3, true", id, ok)
+	}
+	if _, ok := journal.ProjectForShortLink("bTwo"); ok {
+		t.Error("ProjectForShortLink(bTwo) found a project of a board which wasn't migrated")
+	}
+	if id, ok := journal.TaskForShortLink("cOne"); id != 20 || !ok {
+		t.Errorf("TaskForShortLink(cOne) = %d, %t, want 20, true", id, ok)
+	}
+	if _, ok := journal.TaskForShortLink("cTwo"); ok {
+		t.Error("TaskForShortLink(cTwo) found a task of a card which wasn't migrated")
+	}
+}
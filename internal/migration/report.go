@@ -0,0 +1,184 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"wingaru.me/trello-migrate/pkg/filesize"
+)
+
+// Report summarizes a migration run, board by board.
+type Report struct {
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Elapsed  Duration  `json:"elapsed"`
+	// Why the run stopped early, if it did.
+	Error  string         `json:"error,omitempty"`
+	Boards []*BoardReport `json:"boards"`
+	// Things skipped outside of any migrated board.
+	Skipped []SkippedItem `json:"skipped,omitempty"`
+}
+
+// BoardReport counts what was created in Vikunja for a Trello board.
+type BoardReport struct {
+	BoardID    string `json:"board_id"`
+	Name       string `json:"name"`
+	ProjectID  int64  `json:"project_id"`
+	ProjectURL string `json:"project_url"`
+
+	Lists         int   `json:"lists"`
+	Buckets       int   `json:"buckets"`
+	Tasks         int   `json:"tasks"`
+	Subtasks      int   `json:"subtasks"`
+	Comments      int   `json:"comments"`
+	Labels        int   `json:"labels"`
+	Attachments   int   `json:"attachments"`
+	BytesUploaded int64 `json:"bytes_uploaded"`
+	Relations     int   `json:"relations"`
+
+	Skipped []SkippedItem `json:"skipped,omitempty"`
+	Elapsed Duration      `json:"elapsed"`
+
+	started time.Time
+	labels  map[string]bool
+}
+
+// SkippedItem is something which was not migrated, or not as it was.
+type SkippedItem struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// Duration is a time.Duration written as text, like 1m30s.
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).Round(time.Millisecond).String()
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// NewReport starts the report of a run.
+func NewReport() *Report {
+	return &Report{Started: time.Now()}
+}
+
+// StartBoard adds a board to the report and starts its clock.
+func (r *Report) StartBoard(boardID string, name string, projectID int64, projectURL string) *BoardReport {
+	board := &BoardReport{
+		BoardID:    boardID,
+		Name:       name,
+		ProjectID:  projectID,
+		ProjectURL: projectURL,
+		started:    time.Now(),
+		labels:     map[string]bool{},
+	}
+	r.Boards = append(r.Boards, board)
+
+	return board
+}
+
+// Board returns the report of the board boardID, or nil.
+func (r *Report) Board(boardID string) *BoardReport {
+	for _, board := range r.Boards {
+		if board.BoardID == boardID {
+			return board
+		}
+	}
+
+	return nil
+}
+
+// Skip records something skipped outside of a board.
+func (r *Report) Skip(kind string, name string, reason string) {
+	r.Skipped = append(r.Skipped, SkippedItem{Kind: kind, Name: name, Reason: reason})
+}
+
+// Finish stops the clock of the run. err is the error which ended it, if any.
+func (r *Report) Finish(err error) {
+	r.Finished = time.Now()
+	r.Elapsed = Duration(r.Finished.Sub(r.Started))
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// AddLabel counts a label of a task, once per board.
+func (b *BoardReport) AddLabel(title string) {
+	if !b.labels[title] {
+		b.labels[title] = true
+		b.Labels++
+	}
+}
+
+// Skip records something of the board which was not migrated.
+func (b *BoardReport) Skip(kind string, name string, reason string) {
+	b.Skipped = append(b.Skipped, SkippedItem{Kind: kind, Name: name, Reason: reason})
+}
+
+// Finish stops the clock of the board.
+func (b *BoardReport) Finish() {
+	b.Elapsed = Duration(time.Since(b.started))
+}
+
+// WriteJSON writes the report as json to filename.
+func (r *Report) WriteJSON(filename string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, data, 0644)
+}
+
+// WriteMarkdown writes the report as markdown to filename.
+func (r *Report) WriteMarkdown(filename string) error {
+	return os.WriteFile(filename, []byte(r.Markdown()), 0644)
+}
+
+// Markdown renders the report for humans.
+func (r *Report) Markdown() string {
+	var md strings.Builder
+
+	fmt.Fprintf(&md, "# Trello migration report\n\n")
+	fmt.Fprintf(&md, "Started %s, took %s.\n\n", r.Started.Format(time.RFC1123), r.Elapsed)
+	if r.Error != "" {
+		fmt.Fprintf(&md, "**The run failed:** %s\n\n", markdownEscape(r.Error))
+	}
+
+	fmt.Fprintf(&md, "| Board | Project | Lists | Buckets | Tasks | Subtasks | Comments | Labels | Attachments | Uploaded | Relations | Skipped | Elapsed |\n")
+	fmt.Fprintf(&md, "|---|---|---:|---:|---:|---:|---:|---:|---:|---:|---:|---:|---:|\n")
+	for _, b := range r.Boards {
+		fmt.Fprintf(&md, "| %s | [#%d](%s) | %d | %d | %d | %d | %d | %d | %d | %s | %d | %d | %s |\n",
+			markdownEscape(b.Name), b.ProjectID, b.ProjectURL, b.Lists, b.Buckets, b.Tasks, b.Subtasks,
+			b.Comments, b.Labels, b.Attachments, filesize.Format(b.BytesUploaded), b.Relations, len(b.Skipped), b.Elapsed)
+	}
+
+	writeSkipped := func(title string, items []SkippedItem) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&md, "\n## %s\n\n", title)
+		for _, item := range items {
+			fmt.Fprintf(&md, "- %s **%s**: %s\n", item.Kind, markdownEscape(item.Name), markdownEscape(item.Reason))
+		}
+	}
+	for _, b := range r.Boards {
+		writeSkipped("Skipped on "+markdownEscape(b.Name), b.Skipped)
+	}
+	writeSkipped("Skipped", r.Skipped)
+
+	return md.String()
+}
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "\n", " ")
+
+func markdownEscape(text string) string {
+	return markdownEscaper.Replace(text)
+}
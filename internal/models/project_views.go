@@ -1,6 +0,0 @@
-package models
-
-type ProjectView struct {
-	ID    int64  `json:"id"`
-	Title string `json:"title"`
-}
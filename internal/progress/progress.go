@@ -0,0 +1,175 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/vbauerster/mpb/v8"
+	"github.com/vbauerster/mpb/v8/decor"
+	"golang.org/x/term"
+	"golang.org/x/time/rate"
+	"wingaru.me/trello-migrate/pkg/filesize"
+)
+
+// Level is the nesting of a bar: boards contain cards, cards contain
+// attachments.
+type Level int
+
+const (
+	Boards Level = iota
+	Cards
+	Attachments
+)
+
+// Progress shows nested progress bars with the request and byte throughput
+// of all http requests made through its client. When stdout is not a
+// terminal, or for a nil Progress, no bars are shown and the regular log
+// output is kept.
+type Progress struct {
+	bars    *mpb.Progress
+	limit   rate.Limit
+	started time.Time
+
+	requests atomic.Int64
+	bytes    atomic.Int64
+}
+
+// New creates a Progress for requests limited to limit per second. The limit
+// is what the ETA is based on.
+func New(limit rate.Limit) *Progress {
+	p := &Progress{limit: limit, started: time.Now()}
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		p.bars = mpb.New(mpb.WithOutput(os.Stdout), mpb.WithWidth(40), mpb.WithRefreshRate(200*time.Millisecond))
+	}
+
+	return p
+}
+
+// Enabled reports whether bars are shown.
+func (p *Progress) Enabled() bool {
+	return p != nil && p.bars != nil
+}
+
+// Output returns where log lines should be written so they show up above
+// the bars.
+func (p *Progress) Output() io.Writer {
+	if !p.Enabled() {
+		return os.Stdout
+	}
+
+	return p.bars
+}
+
+// Wait stops all bars and waits until they are drawn a last time.
+func (p *Progress) Wait() {
+	if !p.Enabled() {
+		return
+	}
+
+	p.bars.Shutdown()
+}
+
+// Add adds a bar of total items below the bars of lower levels. Bars below
+// the top level disappear once complete.
+func (p *Progress) Add(name string, total int, level Level) *Bar {
+	if !p.Enabled() {
+		return nil
+	}
+
+	bar := &Bar{progress: p, started: time.Now(), requests: p.requests.Load()}
+
+	prepend := []decor.Decorator{
+		decor.Name(fmt.Sprintf("%*s%s", int(level)*2, "", name), decor.WC{W: 32, C: decor.DindentRight}),
+		decor.CountersNoUnit("%d/%d", decor.WCSyncWidth),
+	}
+	var appends []decor.Decorator
+	options := []mpb.BarOption{mpb.BarPriority(int(level))}
+	switch level {
+	case Boards:
+		appends = append(appends,
+			decor.Any(p.throughput, decor.WCSyncSpaceR),
+			decor.Any(bar.eta, decor.WCSyncSpace),
+		)
+	case Cards:
+		appends = append(appends, decor.Any(bar.eta, decor.WCSyncSpace))
+		options = append(options, mpb.BarRemoveOnComplete())
+	default:
+		options = append(options, mpb.BarRemoveOnComplete())
+	}
+	options = append(options, mpb.PrependDecorators(prepend...), mpb.AppendDecorators(appends...))
+
+	bar.bar = p.bars.AddBar(int64(total), options...)
+	return bar
+}
+
+// throughput shows the requests and bytes per second since the start.
+func (p *Progress) throughput(decor.Statistics) string {
+	elapsed := time.Since(p.started).Seconds()
+	if elapsed <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%.1f req/s %s/s",
+		float64(p.requests.Load())/elapsed, filesize.Format(int64(float64(p.bytes.Load())/elapsed)))
+}
+
+// Bar is a single progress bar. All methods do nothing on a nil Bar, which
+// is what Add returns when no bars are shown.
+type Bar struct {
+	progress *Progress
+	bar      *mpb.Bar
+	started  time.Time
+	// The number of requests made before the bar was added.
+	requests int64
+}
+
+// Increment marks one more item as done.
+func (b *Bar) Increment() {
+	if b != nil {
+		b.bar.Increment()
+	}
+}
+
+// SetTotal changes the number of items, for bars whose total is only known
+// later.
+func (b *Bar) SetTotal(total int) {
+	if b != nil {
+		b.bar.SetTotal(int64(total), false)
+	}
+}
+
+// Done completes the bar, even if not all items were marked as done.
+func (b *Bar) Done() {
+	if b != nil {
+		b.bar.SetTotal(-1, true)
+	}
+}
+
+// eta estimates the remaining time from the requests the items done so far
+// needed and the rate requests can be made at: the rate limit, or the rate
+// seen so far if the server is slower than that.
+func (b *Bar) eta(s decor.Statistics) string {
+	if s.Completed {
+		return ""
+	}
+	if s.Current == 0 {
+		return "ETA ?"
+	}
+
+	requests := float64(b.progress.requests.Load() - b.requests)
+	remaining := requests / float64(s.Current) * float64(s.Total-s.Current)
+
+	perSecond := float64(b.progress.limit)
+	if elapsed := time.Since(b.started).Seconds(); elapsed > 0 && requests/elapsed < perSecond {
+		perSecond = requests / elapsed
+	}
+	if perSecond <= 0 {
+		return "ETA ?"
+	}
+
+	eta := time.Duration(remaining / perSecond * float64(time.Second))
+	return "ETA " + eta.Round(time.Second).String()
+}
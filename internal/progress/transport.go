@@ -0,0 +1,43 @@
+package progress
+
+import (
+	"io"
+	"net/http"
+)
+
+// HTTPClient returns a client counting its requests and the bytes sent and
+// received for the throughput shown by p.
+func (p *Progress) HTTPClient() *http.Client {
+	return &http.Client{Transport: &transport{progress: p, base: http.DefaultTransport}}
+}
+
+type transport struct {
+	progress *Progress
+	base     http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.progress.requests.Add(1)
+	if req.ContentLength > 0 {
+		t.progress.bytes.Add(req.ContentLength)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = &countingBody{ReadCloser: resp.Body, progress: t.progress}
+	return resp, nil
+}
+
+type countingBody struct {
+	io.ReadCloser
+	progress *Progress
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.progress.bytes.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,20 @@
+// Package filesize writes sizes in bytes for humans.
+package filesize
+
+import "fmt"
+
+// Format writes a size in bytes for humans, like 1.5 MiB.
+func Format(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
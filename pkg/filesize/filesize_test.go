@@ -0,0 +1,22 @@
+package filesize
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{20 * 1024 * 1024, "20.0 MiB"},
+		{5 * 1024 * 1024 * 1024, "5.0 GiB"},
+	}
+	for _, test := range tests {
+		if got := Format(test.n); got != test.want {
+			t.Errorf("Format(%d) = %q, want %q", test.n, got, test.want)
+		}
+	}
+}
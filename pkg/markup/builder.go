@@ -0,0 +1,68 @@
+package markup
+
+import (
+	"html"
+	"strings"
+)
+
+// Attr is a single html attribute.
+type Attr struct {
+	Name  string
+	Value string
+}
+
+// Builder builds html fragments. Text and attribute values are always
+// escaped, only Raw writes its input unchanged.
+type Builder struct {
+	b strings.Builder
+}
+
+// Open writes the opening tag of an element.
+func (b *Builder) Open(tag string, attrs ...Attr) *Builder {
+	b.b.WriteString("<" + tag)
+	for _, attr := range attrs {
+		b.b.WriteString(" " + attr.Name)
+		if attr.Value != "" {
+			b.b.WriteString(`="` + html.EscapeString(attr.Value) + `"`)
+		}
+	}
+	b.b.WriteString(">")
+
+	return b
+}
+
+// Close writes the closing tag of an element.
+func (b *Builder) Close(tag string) *Builder {
+	b.b.WriteString("</" + tag + ">")
+
+	return b
+}
+
+// Element writes an element containing only text.
+func (b *Builder) Element(tag string, text string, attrs ...Attr) *Builder {
+	return b.Open(tag, attrs...).Text(text).Close(tag)
+}
+
+// Text writes escaped text.
+func (b *Builder) Text(text string) *Builder {
+	b.b.WriteString(html.EscapeString(text))
+
+	return b
+}
+
+// Raw writes s without escaping it. It must only be used with html which is
+// already safe, like the output of another Builder or of a Sanitizer.
+func (b *Builder) Raw(s string) *Builder {
+	b.b.WriteString(s)
+
+	return b
+}
+
+// Len returns the number of bytes written so far.
+func (b *Builder) Len() int {
+	return b.b.Len()
+}
+
+func (b *Builder) String() string {
+	return b.b.String()
+}
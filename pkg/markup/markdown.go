@@ -0,0 +1,200 @@
+package markup
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	emoji "github.com/yuin/goldmark-emoji"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Renderer converts Trello flavored markdown to sanitized html.
+type Renderer struct {
+	md       goldmark.Markdown
+	sanitize func(html string) string
+}
+
+// NewRenderer creates a Renderer supporting GitHub flavored markdown, emoji
+// shortcodes and Trello's mentions and card links. The html is cleaned up
+// with sanitize, like the Sanitize method of a Sanitizer. cardNames maps the
+// short link of a Trello card to its name and is used as the text of links
+// to that card. It may be nil.
+func NewRenderer(sanitize func(html string) string, cardNames map[string]string) *Renderer {
+	md := goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			emoji.New(emoji.WithRenderingMethod(emoji.Unicode)),
+		),
+		goldmark.WithParserOptions(
+			parser.WithInlineParsers(util.Prioritized(&mentionParser{}, 100)),
+			parser.WithASTTransformers(util.Prioritized(&trelloLinkTransformer{cardNames: cardNames}, 100)),
+		),
+		goldmark.WithRendererOptions(
+			html.WithHardWraps(),
+			renderer.WithNodeRenderers(util.Prioritized(&mentionRenderer{}, 100)),
+		),
+	)
+
+	return &Renderer{md: md, sanitize: sanitize}
+}
+
+// Render converts input to html.
+func (r *Renderer) Render(input string) (string, error) {
+	var buf bytes.Buffer
+	if err := r.md.Convert([]byte(input), &buf); err != nil {
+		return "", err
+	}
+
+	return r.sanitize(buf.String()), nil
+}
+
+// KindMention is the ast.NodeKind of a Mention.
+var KindMention = ast.NewNodeKind("Mention")
+
+// Mention is an @mention of a Trello member.
+type Mention struct {
+	ast.BaseInline
+	Username []byte
+}
+
+func (n *Mention) Kind() ast.NodeKind {
+	return KindMention
+}
+
+func (n *Mention) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Username": string(n.Username)}, nil)
+}
+
+// mentionParser parses @username. Trello usernames may contain underscores,
+// which would otherwise be taken for emphasis.
+type mentionParser struct{}
+
+func (p *mentionParser) Trigger() []byte {
+	return []byte{'@'}
+}
+
+func (p *mentionParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	if prev := block.PrecendingCharacter(); util.IsAlphaNumeric(byte(prev)) {
+		return nil
+	}
+
+	line, _ := block.PeekLine()
+	i := 1
+	for i < len(line) && isUsernameChar(line[i]) {
+		i++
+	}
+	if i == 1 {
+		return nil
+	}
+
+	block.Advance(i)
+	return &Mention{Username: line[1:i]}
+}
+
+func isUsernameChar(c byte) bool {
+	return c == '_' || util.IsAlphaNumeric(c)
+}
+
+type mentionRenderer struct{}
+
+func (r *mentionRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindMention, r.renderMention)
+}
+
+func (r *mentionRenderer) renderMention(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, _ = w.WriteString("<strong>@")
+		_, _ = w.Write(util.EscapeHTML(n.(*Mention).Username))
+		_, _ = w.WriteString("</strong>")
+	}
+
+	return ast.WalkContinue, nil
+}
+
+var cardShortLinkPattern = regexp.MustCompile(`^https?://trello\.com/c/([A-Za-z0-9]+)`)
+
+// CardShortLink returns the short link of the Trello card url points to.
+func CardShortLink(url string) (string, bool) {
+	match := cardShortLinkPattern.FindStringSubmatch(url)
+	if match == nil {
+		return "", false
+	}
+
+	return match[1], true
+}
+
+// trelloLinkTransformer drops the smart link markers Trello puts into link
+// titles and shows links to known cards with the name of the card.
+type trelloLinkTransformer struct {
+	cardNames map[string]string
+}
+
+func (t *trelloLinkTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+
+	var autoLinks []*ast.AutoLink
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.Link:
+			if strings.HasPrefix(string(node.Title), "smartCard-") {
+				node.Title = nil
+			}
+			if name, ok := t.cardName(string(node.Destination)); ok && linkShowsURL(node, source) {
+				node.RemoveChildren(node)
+				node.AppendChild(node, ast.NewString([]byte(name)))
+			}
+		case *ast.AutoLink:
+			autoLinks = append(autoLinks, node)
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	for _, autoLink := range autoLinks {
+		url := autoLink.URL(source)
+		name, ok := t.cardName(string(url))
+		if !ok {
+			continue
+		}
+
+		link := ast.NewLink()
+		link.Destination = url
+		link.AppendChild(link, ast.NewString([]byte(name)))
+		autoLink.Parent().ReplaceChild(autoLink.Parent(), autoLink, link)
+	}
+}
+
+func (t *trelloLinkTransformer) cardName(url string) (string, bool) {
+	shortLink, ok := CardShortLink(url)
+	if !ok {
+		return "", false
+	}
+
+	name, ok := t.cardNames[shortLink]
+	return name, ok && name != ""
+}
+
+// linkShowsURL reports whether the text of a link is its destination, as
+// Trello writes pasted links.
+func linkShowsURL(link *ast.Link, source []byte) bool {
+	var label bytes.Buffer
+	for c := link.FirstChild(); c != nil; c = c.NextSibling() {
+		if t, ok := c.(*ast.Text); ok {
+			label.Write(t.Segment.Value(source))
+		}
+	}
+
+	return label.String() == string(link.Destination)
+}
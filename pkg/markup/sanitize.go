@@ -0,0 +1,106 @@
+package markup
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// Allowlist lists the elements, attributes and url schemes which survive
+// sanitization.
+type Allowlist struct {
+	// Allowed elements with the attributes allowed on them.
+	Elements map[string][]string `json:"elements"`
+	// Url schemes allowed in href and src attributes.
+	URLSchemes []string `json:"url_schemes"`
+}
+
+// DefaultAllowlist allows the markup goldmark produces and Vikunja's editor
+// understands, including its task lists.
+func DefaultAllowlist() *Allowlist {
+	return &Allowlist{
+		Elements: map[string][]string{
+			"a":          {"href", "title"},
+			"blockquote": nil,
+			"br":         nil,
+			"code":       nil,
+			"del":        nil,
+			"details":    nil,
+			"div":        nil,
+			"em":         nil,
+			"h1":         nil,
+			"h2":         nil,
+			"h3":         nil,
+			"h4":         nil,
+			"h5":         nil,
+			"h6":         nil,
+			"hr":         nil,
+			"img":        {"src", "alt", "title"},
+			"input":      {"type", "checked", "disabled"},
+			"label":      nil,
+			"li":         {"data-type", "data-checked"},
+			"ol":         {"start"},
+			"p":          nil,
+			"pre":        nil,
+			"s":          nil,
+			"span":       nil,
+			"strong":     nil,
+			"summary":    nil,
+			"table":      nil,
+			"tbody":      nil,
+			"td":         {"align"},
+			"th":         {"align"},
+			"thead":      nil,
+			"tr":         nil,
+			"ul":         {"data-type"},
+		},
+		URLSchemes: []string{"http", "https", "mailto"},
+	}
+}
+
+// ReadAllowlist reads an allowlist from a json file. An empty filename
+// returns the DefaultAllowlist.
+func ReadAllowlist(filename string) (*Allowlist, error) {
+	if filename == "" {
+		return DefaultAllowlist(), nil
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	allowlist := &Allowlist{}
+	if err := json.Unmarshal(data, allowlist); err != nil {
+		return nil, err
+	}
+
+	return allowlist, nil
+}
+
+// Sanitizer removes everything from html which is not in its allowlist.
+type Sanitizer struct {
+	policy *bluemonday.Policy
+}
+
+// NewSanitizer creates a Sanitizer enforcing allowlist.
+func NewSanitizer(allowlist *Allowlist) *Sanitizer {
+	policy := bluemonday.NewPolicy()
+	policy.RequireParseableURLs(true)
+	policy.AllowURLSchemes(allowlist.URLSchemes...)
+
+	for element, attrs := range allowlist.Elements {
+		policy.AllowNoAttrs().OnElements(element)
+		if len(attrs) > 0 {
+			policy.AllowAttrs(attrs...).OnElements(element)
+		}
+	}
+
+	return &Sanitizer{policy: policy}
+}
+
+// Sanitize returns a safe version of input.
+func (s *Sanitizer) Sanitize(input string) string {
+	return s.policy.Sanitize(input)
+}
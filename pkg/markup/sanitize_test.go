@@ -0,0 +1,89 @@
+package markup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"script", `<p>hi</p><script>alert(1)</script>`, `<p>hi</p>`},
+		{"event handler", `<img src="https://example.com/a.png" onerror="alert(1)">`, `<img src="https://example.com/a.png">`},
+		{"javascript url", `<a href="javascript:alert(1)">click</a>`, `<a>click</a>`},
+		{"data url", `<img src="data:text/html;base64,PHNjcmlwdD4=">`, `<img>`},
+		{"style", `<p style="color: red">red</p>`, `<p>red</p>`},
+		{"iframe", `<iframe src="https://example.com"></iframe>text`, `text`},
+		{"safe link", `<a href="https://example.com/?a=1&amp;b=2" title="x">ok</a>`, `<a href="https://example.com/?a=1&amp;b=2" title="x">ok</a>`},
+		{"mailto", `<a href="mailto:a@example.com">mail</a>`, `<a href="mailto:a@example.com">mail</a>`},
+		{
+			"task list",
+			`<ul data-type="taskList"><li data-checked="true" data-type="taskItem"><label><input type="checkbox" checked="checked"><span></span></label><div><p>&lt;b&gt;</p></div></li></ul>`,
+			`<ul data-type="taskList"><li data-checked="true" data-type="taskItem"><label><input type="checkbox" checked="checked"><span></span></label><div><p>&lt;b&gt;</p></div></li></ul>`,
+		},
+	}
+	sanitizer := NewSanitizer(DefaultAllowlist())
+	for _, test := range tests {
+		if got := sanitizer.Sanitize(test.input); got != test.want {
+			t.Errorf("%s: Sanitize(%q) = %q, want %q", test.name, test.input, got, test.want)
+		}
+	}
+}
+
+func TestReadAllowlist(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "allowlist.json")
+	err := os.WriteFile(filename, []byte(`{"elements": {"a": ["href"], "p": []}, "url_schemes": ["https"]}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allowlist, err := ReadAllowlist(filename)
+	if err != nil {
+		t.Fatalf("ReadAllowlist: %v", err)
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`<p><strong>bold</strong></p>`, `<p>bold</p>`},
+		{`<h2>title</h2><p>text</p>`, `title<p>text</p>`},
+		{`<a href="https://example.com" title="x">ok</a>`, `<a href="https://example.com">ok</a>`},
+		{`<a href="http://example.com">plain http</a>`, `<a>plain http</a>`},
+		{`<a href="mailto:a@example.com">mail</a>`, `<a>mail</a>`},
+	}
+	sanitizer := NewSanitizer(allowlist)
+	for _, test := range tests {
+		if got := sanitizer.Sanitize(test.input); got != test.want {
+			t.Errorf("Sanitize(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
+
+func TestReadAllowlistDefault(t *testing.T) {
+	allowlist, err := ReadAllowlist("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(allowlist.Elements) != len(DefaultAllowlist().Elements) {
+		t.Errorf("ReadAllowlist(\"\") allows %d elements, want the %d of the default", len(allowlist.Elements), len(DefaultAllowlist().Elements))
+	}
+
+	if _, err := ReadAllowlist(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("ReadAllowlist of a missing file returned no error")
+	}
+}
+
+func TestBuilderEscapes(t *testing.T) {
+	b := &Builder{}
+	b.Open("p").Element("a", `<script>"x"</script>`, Attr{Name: "href", Value: `https://example.com/?q="><script>`}).Close("p")
+
+	want := `<p><a href="https://example.com/?q=&#34;&gt;&lt;script&gt;">&lt;script&gt;&#34;x&#34;&lt;/script&gt;</a></p>`
+	if b.String() != want {
+		t.Errorf("the builder wrote %s, want %s", b.String(), want)
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// APIToken is a token with limited permissions, used instead of the login
+// of a user.
+type APIToken struct {
+	ID    int64  `json:"id,omitempty"`
+	Title string `json:"title"`
+	// Token is only returned when the token is created.
+	Token string `json:"token,omitempty"`
+	// Permissions maps route groups, like tasks, to the routes of the group
+	// the token may use.
+	Permissions map[string][]string `json:"permissions"`
+	ExpiresAt   time.Time           `json:"expires_at"`
+	Created     time.Time           `json:"created,omitempty"`
+}
@@ -54,10 +54,21 @@ type Project struct {
 type ProjectWithTasksAndBuckets struct {
 	Project
 	ChildProjects []*ProjectWithTasksAndBuckets `xorm:"-" json:"child_projects"`
+	// An array of tasks which belong to the project.
+	Tasks []*TaskWithComments `xorm:"-" json:"tasks"`
 
 	// Only used for migration.
 	Buckets          []*Bucket       `xorm:"-" json:"buckets"`
 	TaskBuckets      []*TaskBucket   `xorm:"-" json:"task_buckets"`
 	Positions        []*TaskPosition `xorm:"-" json:"positions"`
 	BackgroundFileID int64           `xorm:"null" json:"background_file_id"`
+
+	// Only used for migration. The Trello board this project was created from.
+	TrelloBoardID        string `xorm:"-" json:"-"`
+	TrelloBoardShortLink string `xorm:"-" json:"-"`
+	// The number of lists of the board, for reports. It is not the number of
+	// Buckets: the size strategy spreads the cards of all lists over
+	// numbered buckets, and the list strategy only creates buckets for lists
+	// with cards to migrate.
+	TrelloListCount int `xorm:"-" json:"-"`
 }
@@ -0,0 +1,14 @@
+package models
+
+type ProjectView struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	// The project this view belongs to.
+	ProjectID int64 `json:"project_id"`
+	// One of list, gantt, table or kanban.
+	ViewKind string `json:"view_kind"`
+	// How the buckets of a kanban view are managed: none, manual or filter.
+	BucketConfigurationMode string `json:"bucket_configuration_mode"`
+	// The position of this view in the list of views of its project.
+	Position float64 `json:"position"`
+}
@@ -90,4 +90,22 @@ type Task struct {
 type TaskWithComments struct {
 	Task
 	Comments []*TaskComment `xorm:"-" json:"comments"`
+
+	// Only used for migration. Tasks which are created after this one and
+	// linked to it with a subtask relation.
+	Subtasks []*TaskWithComments `xorm:"-" json:"-"`
+
+	// Only used for migration. The Trello card this task was created from.
+	TrelloCardID        string `xorm:"-" json:"-"`
+	TrelloCardShortLink string `xorm:"-" json:"-"`
+	// Only used for migration. Attachments linking to other Trello cards,
+	// which become task relations once all tasks exist.
+	TrelloCardLinks []*TrelloCardLink `xorm:"-" json:"-"`
+}
+
+// TrelloCardLink is an attachment of a Trello card pointing to another card.
+type TrelloCardLink struct {
+	ShortLink string
+	Name      string
+	URL       string
 }
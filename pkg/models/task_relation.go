@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// RelationKind is the kind of relation between two tasks.
+type RelationKind string
+
+const (
+	RelationKindSubtask    RelationKind = "subtask"
+	RelationKindParenttask RelationKind = "parenttask"
+	RelationKindRelated    RelationKind = "related"
+)
+
+type TaskRelation struct {
+	// The ID of the "base" task, the task which has a relation to another.
+	TaskID int64 `xorm:"bigint not null" json:"task_id" param:"task"`
+	// The ID of the other task, the task which is being related.
+	OtherTaskID int64 `xorm:"bigint not null" json:"other_task_id"`
+	// The kind of the relation.
+	RelationKind RelationKind `xorm:"varchar(50) not null" json:"relation_kind"`
+
+	// The user who created this relation
+	CreatedBy *User `xorm:"-" json:"created_by"`
+
+	// A timestamp when this label was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+}
@@ -0,0 +1,141 @@
+package palette
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Palette maps Trello color names to Vikunja hex colors, without a leading #.
+type Palette map[string]string
+
+// Default returns the colors Trello uses for labels.
+func Default() Palette {
+	return Palette{
+		"green":        "4bce97",
+		"yellow":       "f5cd47",
+		"orange":       "fea362",
+		"red":          "f87168",
+		"purple":       "9f8fef",
+		"blue":         "579dff",
+		"sky":          "6cc3e0",
+		"lime":         "94c748",
+		"pink":         "e774bb",
+		"black":        "8590a2",
+		"green_dark":   "1f845a",
+		"yellow_dark":  "946f00",
+		"orange_dark":  "c25100",
+		"red_dark":     "c9372c",
+		"purple_dark":  "6e5dc6",
+		"blue_dark":    "0c66e4",
+		"sky_dark":     "227d9b",
+		"lime_dark":    "5b7f24",
+		"pink_dark":    "ae4787",
+		"black_dark":   "626f86",
+		"green_light":  "baf3db",
+		"yellow_light": "f8e6a0",
+		"orange_light": "fedec8",
+		"red_light":    "ffd5d2",
+		"purple_light": "dfd8fd",
+		"blue_light":   "cce0ff",
+		"sky_light":    "c6edfb",
+		"lime_light":   "d3f1a7",
+		"pink_light":   "fdd0ec",
+		"black_light":  "dcdfe4",
+		"grey":         "8590a2",
+	}
+}
+
+// Read loads a palette from a json object of color names to hex colors. The
+// colors in the file are added to, or replace those of, the Default palette.
+// An empty filename returns the Default palette.
+func Read(filename string) (Palette, error) {
+	palette := Default()
+	if filename == "" {
+		return palette, nil
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	colors := map[string]string{}
+	if err := json.Unmarshal(data, &colors); err != nil {
+		return nil, err
+	}
+
+	for name, hex := range colors {
+		palette[name] = strings.TrimPrefix(hex, "#")
+	}
+
+	return palette, nil
+}
+
+// Hex returns the hex color for the Trello color name. Unknown or empty
+// names get a color of the palette picked by hashing seed, so the same seed
+// always ends up with the same color.
+func (p Palette) Hex(name string, seed string) string {
+	if hex, exists := p[name]; exists && hex != "" {
+		return hex
+	}
+
+	colors := make([]string, 0, len(p))
+	for _, hex := range p {
+		if hex != "" {
+			colors = append(colors, hex)
+		}
+	}
+	if len(colors) == 0 {
+		return ""
+	}
+	sort.Strings(colors)
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(seed))
+	return colors[h.Sum32()%uint32(len(colors))]
+}
+
+// Distance returns how far apart two hex colors are, as the euclidean
+// distance of their rgb values. Invalid colors are as far away as possible.
+func Distance(a string, b string) float64 {
+	ar, ag, ab, okA := parseHex(a)
+	br, bg, bb, okB := parseHex(b)
+	if !okA || !okB {
+		return math.MaxFloat64
+	}
+
+	return math.Sqrt(math.Pow(ar-br, 2) + math.Pow(ag-bg, 2) + math.Pow(ab-bb, 2))
+}
+
+// Nearest returns the index of the color in candidates closest to hex, or -1
+// if there are no candidates.
+func Nearest(hex string, candidates []string) int {
+	nearest := -1
+	best := math.Inf(1)
+	for i, candidate := range candidates {
+		if d := Distance(hex, candidate); d < best {
+			nearest, best = i, d
+		}
+	}
+
+	return nearest
+}
+
+func parseHex(hex string) (r, g, b float64, ok bool) {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return 0, 0, 0, false
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+
+	return float64(v >> 16 & 0xff), float64(v >> 8 & 0xff), float64(v & 0xff), true
+}
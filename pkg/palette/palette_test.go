@@ -0,0 +1,66 @@
+package palette
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNearest(t *testing.T) {
+	tests := []struct {
+		name       string
+		hex        string
+		candidates []string
+		want       int
+	}{
+		{"exact", "f87168", []string{"4bce97", "f87168", "579dff"}, 1},
+		{"closest", "ff0000", []string{"579dff", "c9372c", "4bce97"}, 1},
+		{"with #", "#4bce97", []string{"#f87168", "#4bce90"}, 1},
+		{"first of equals", "000000", []string{"010000", "000100"}, 0},
+		{"invalid candidate", "ffffff", []string{"red", "000000"}, 1},
+		{"no candidates", "ffffff", nil, -1},
+	}
+	for _, test := range tests {
+		if got := Nearest(test.hex, test.candidates); got != test.want {
+			t.Errorf("%s: Nearest(%q, %q) = %d, want %d", test.name, test.hex, test.candidates, got, test.want)
+		}
+	}
+}
+
+func TestHex(t *testing.T) {
+	palette := Default()
+
+	if got := palette.Hex("red", "label1"); got != "f87168" {
+		t.Errorf("Hex(red) = %q, want the red of the palette", got)
+	}
+
+	first := palette.Hex("", "label1")
+	if first == "" {
+		t.Fatal("a label without a color got no color")
+	}
+	for i := 0; i < 10; i++ {
+		if got := palette.Hex("", "label1"); got != first {
+			t.Fatalf("the same seed got %q and %q", first, got)
+		}
+	}
+	if got := palette.Hex("unknown", "label1"); got != first {
+		t.Errorf("an unknown color got %q, want the color of its seed %q", got, first)
+	}
+}
+
+func TestRead(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "palette.json")
+	if err := os.WriteFile(filename, []byte(`{"red": "#ff0000", "brand": "123abc"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	palette, err := Read(filename)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	for name, want := range map[string]string{"red": "ff0000", "brand": "123abc", "green": "4bce97"} {
+		if palette[name] != want {
+			t.Errorf("%s is %q, want %q", name, palette[name], want)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package trello2vikunja
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"wingaru.me/trello-migrate/pkg/models"
+	"wingaru.me/trello-migrate/pkg/trellosource"
+)
+
+// ArchiveVersion is the Vikunja version whose export format WriteArchive
+// writes. Vikunja imports archives of this and later versions.
+const ArchiveVersion = "v0.24.0"
+
+// archiveViews are the views Vikunja creates for new projects.
+var archiveViews = []struct {
+	title      string
+	kind       string
+	bucketMode string
+}{
+	{"List", "list", "none"},
+	{"Gantt", "gantt", "none"},
+	{"Table", "table", "none"},
+	{"Kanban", "kanban", "manual"},
+}
+
+// inverseRelationKinds maps every relation kind to the kind Vikunja creates
+// for the other task.
+var inverseRelationKinds = map[models.RelationKind]models.RelationKind{
+	models.RelationKindSubtask:    models.RelationKindParenttask,
+	models.RelationKindParenttask: models.RelationKindSubtask,
+	models.RelationKindRelated:    models.RelationKindRelated,
+	"duplicateof":                 "duplicates",
+	"duplicates":                  "duplicateof",
+	"blocking":                    "blocked",
+	"blocked":                     "blocking",
+	"precedes":                    "follows",
+	"follows":                     "precedes",
+	"copiedfrom":                  "copiedto",
+	"copiedto":                    "copiedfrom",
+}
+
+// RelationKinds returns the relation kinds Vikunja knows, sorted.
+func RelationKinds() []models.RelationKind {
+	kinds := make([]models.RelationKind, 0, len(inverseRelationKinds))
+	for kind := range inverseRelationKinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i] < kinds[j] })
+
+	return kinds
+}
+
+// NewProjects returns a project with the default views of Vikunja for every
+// board, to convert boards for an archive without looking up existing
+// projects. The ids are only valid within the archive. Boards and projects
+// are matched by name, so boards sharing the name of an earlier board are
+// renamed to "Name (2)", "Name (3)" and so on.
+func NewProjects(boards []*trellosource.Board) map[string]models.Project {
+	names := make(map[string]bool, len(boards))
+	for _, board := range boards {
+		names[board.Name] = true
+	}
+
+	projects := make(map[string]models.Project, len(boards))
+	for i, board := range boards {
+		if _, exists := projects[board.Name]; exists {
+			name := board.Name
+			for n := 2; names[name]; n++ {
+				name = fmt.Sprintf("%s (%d)", board.Name, n)
+			}
+			names[name] = true
+			board.Name = name
+		}
+
+		id := int64(i + 1)
+		projects[board.Name] = models.Project{
+			ID:    id,
+			Title: board.Name,
+			Views: projectViews(id),
+		}
+	}
+
+	return projects
+}
+
+// projectViews returns the views of the project id of NewProjects.
+func projectViews(id int64) []*models.ProjectView {
+	views := make([]*models.ProjectView, 0, len(archiveViews))
+	for position, view := range archiveViews {
+		views = append(views, &models.ProjectView{
+			ID:                      (id-1)*int64(len(archiveViews)) + int64(position) + 1,
+			Title:                   view.title,
+			ProjectID:               id,
+			ViewKind:                view.kind,
+			BucketConfigurationMode: view.bucketMode,
+			Position:                taskPosition(position),
+		})
+	}
+
+	return views
+}
+
+// WriteArchive writes the plan as a zip Vikunja can import through its
+// "Vikunja export" migration, with the files of all attachments. Links
+// between cards become relations of CardLinkRelation when both cards end up
+// in the same project, and links in the description otherwise. The plan
+// should be converted from the projects of NewProjects.
+func (p *Plan) WriteArchive(w io.Writer) error {
+	archive := &archiveWriter{zip: zip.NewWriter(w), relationKind: p.CardLinkRelation}
+
+	projects := make([]*archiveProject, 0, len(p.Projects))
+	for _, project := range p.Projects {
+		exported, err := archive.project(project)
+		if err != nil {
+			return err
+		}
+		projects = append(projects, exported)
+	}
+
+	if err := archive.writeFile("VERSION", []byte(ArchiveVersion)); err != nil {
+		return err
+	}
+	if err := archive.writeJSON("data.json", projects); err != nil {
+		return err
+	}
+	if err := archive.writeJSON("filters.json", []interface{}{}); err != nil {
+		return err
+	}
+
+	return archive.zip.Close()
+}
+
+// archiveWriter numbers the buckets, tasks and files of the archive.
+type archiveWriter struct {
+	zip          *zip.Writer
+	relationKind models.RelationKind
+
+	lastBucketID     int64
+	lastTaskID       int64
+	lastAttachmentID int64
+	lastFileID       int64
+}
+
+// archiveProject is a project in data.json of an archive.
+type archiveProject struct {
+	*models.ProjectWithTasksAndBuckets
+	TaskBuckets []*archiveTaskBucket `json:"task_buckets"`
+}
+
+// archiveTaskBucket assigns a task to a bucket. Vikunja's task bucket has no
+// json tags, so its export uses the field names as keys.
+type archiveTaskBucket struct {
+	BucketID      int64
+	TaskID        int64
+	ProjectViewID int64
+}
+
+// project returns the archive form of project: its tasks in Tasks, assigned
+// to buckets through TaskBuckets and ordered through Positions.
+func (a *archiveWriter) project(project *models.ProjectWithTasksAndBuckets) (*archiveProject, error) {
+	exported := &archiveProject{
+		ProjectWithTasksAndBuckets: &models.ProjectWithTasksAndBuckets{Project: project.Project},
+		TaskBuckets:                []*archiveTaskBucket{},
+	}
+	exported.Views = projectViews(project.ID)
+	exported.Tasks = []*models.TaskWithComments{}
+
+	tasksByShortLink := make(map[string]*models.TaskWithComments)
+	for _, bucket := range project.Buckets {
+		a.lastBucketID++
+		exported.Buckets = append(exported.Buckets, &models.Bucket{
+			ID:            a.lastBucketID,
+			Title:         bucket.Title,
+			ProjectViewID: bucket.ProjectViewID,
+			Position:      bucket.Position,
+		})
+
+		for _, task := range bucket.TasksWithComments {
+			a.lastTaskID++
+			task.ID = a.lastTaskID
+			task.BucketID = a.lastBucketID
+			for _, comment := range task.Comments {
+				comment.TaskID = task.ID
+			}
+
+			err := a.attachments(task)
+			if err != nil {
+				return nil, err
+			}
+
+			relatedTasks := map[models.RelationKind][]*models.Task{}
+			for _, subtask := range task.Subtasks {
+				relatedTasks[models.RelationKindSubtask] = append(relatedTasks[models.RelationKindSubtask], &subtask.Task)
+			}
+			task.RelatedTasks = relatedTasks
+
+			exported.Tasks = append(exported.Tasks, task)
+			exported.TaskBuckets = append(exported.TaskBuckets, &archiveTaskBucket{
+				BucketID:      a.lastBucketID,
+				TaskID:        task.ID,
+				ProjectViewID: bucket.ProjectViewID,
+			})
+			for _, view := range project.Views {
+				exported.Positions = append(exported.Positions, &models.TaskPosition{
+					TaskID:        task.ID,
+					ProjectViewID: view.ID,
+					Position:      task.Position,
+				})
+			}
+			tasksByShortLink[task.TrelloCardShortLink] = task
+		}
+	}
+
+	a.cardLinks(exported.Tasks, tasksByShortLink)
+
+	return exported, nil
+}
+
+// attachments numbers the attachments of task and writes their files.
+func (a *archiveWriter) attachments(task *models.TaskWithComments) error {
+	for _, attachment := range task.Attachments {
+		a.lastAttachmentID++
+		// The converter marks the cover with a placeholder id.
+		if attachment.ID != 0 && attachment.ID == task.CoverImageAttachmentID {
+			task.CoverImageAttachmentID = a.lastAttachmentID
+		}
+		attachment.ID = a.lastAttachmentID
+		attachment.TaskID = task.ID
+
+		a.lastFileID++
+		attachment.File.ID = a.lastFileID
+		err := a.writeFile("files/"+strconv.FormatInt(a.lastFileID, 10), attachment.File.FileContent)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cardLinks turns the card links of tasks into relations. Vikunja creates
+// related tasks it doesn't know yet, so every relation is added to the
+// task which comes later, using the inverse kind where needed.
+func (a *archiveWriter) cardLinks(tasks []*models.TaskWithComments, tasksByShortLink map[string]*models.TaskWithComments) {
+	inverse, known := inverseRelationKinds[a.relationKind]
+	seen := make(map[string]bool)
+	for _, task := range tasks {
+		for _, link := range task.TrelloCardLinks {
+			linked, exists := tasksByShortLink[link.ShortLink]
+			if !exists || !known || linked == task {
+				task.Description += AttachmentLink(link.Name, link.URL)
+				continue
+			}
+
+			from, to, kind := task, linked, a.relationKind
+			if linked.ID > task.ID {
+				from, to, kind = linked, task, inverse
+			}
+
+			key := fmt.Sprintf("%d:%d", from.ID, to.ID)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			related := from.RelatedTasks.(map[models.RelationKind][]*models.Task)
+			related[kind] = append(related[kind], &models.Task{ID: to.ID, Title: to.Title, ProjectID: to.ProjectID})
+		}
+	}
+}
+
+func (a *archiveWriter) writeJSON(name string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return a.writeFile(name, data)
+}
+
+func (a *archiveWriter) writeFile(name string, content []byte) error {
+	w, err := a.zip.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(content)
+	return err
+}
@@ -0,0 +1,114 @@
+package trello2vikunja
+
+import (
+	"strconv"
+
+	"github.com/warrenwingaru/go-trello"
+	"wingaru.me/trello-migrate/pkg/markup"
+	"wingaru.me/trello-migrate/pkg/models"
+	"wingaru.me/trello-migrate/pkg/trellosource"
+)
+
+// ChecklistMode decides how the checklists of a card are migrated.
+type ChecklistMode string
+
+const (
+	// ChecklistsDescription renders checklists as task lists in the description.
+	ChecklistsDescription ChecklistMode = "description"
+	// ChecklistsSubtasks creates a subtask for every checklist item.
+	ChecklistsSubtasks ChecklistMode = "subtasks"
+)
+
+// convertChecklistsToSubtasks turns every checklist item of a card into a
+// subtask of task and derives the progress of task from them. The subtasks
+// keep the due dates of the items and follow task in the order of the
+// items.
+func (c *conversion) convertChecklistsToSubtasks(task *models.TaskWithComments, board *trellosource.Board, card *trello.Card) {
+	items := 0
+	for _, checklist := range card.Checklists {
+		items += len(checklist.CheckItems)
+	}
+
+	total, done := 0, 0
+	for _, checklist := range card.Checklists {
+		for _, item := range sortedCheckItems(checklist.CheckItems) {
+			subtask := &models.TaskWithComments{
+				Task: models.Task{
+					Title:     item.Name,
+					ProjectID: task.ProjectID,
+					Done:      item.State == "complete",
+					Position:  subtaskPosition(task.Position, total, items),
+				},
+			}
+			details := board.CheckItem(item.ID)
+			if details.Due != nil {
+				subtask.DueDate = *details.Due
+			}
+			c.dropCheckItemDetails(board, card, item, trellosource.CheckItemDetails{IDMember: details.IDMember})
+
+			total++
+			if subtask.Done {
+				done++
+			}
+
+			task.Subtasks = append(task.Subtasks, subtask)
+		}
+	}
+
+	if total > 0 {
+		task.PercentDone = float64(done) / float64(total)
+	}
+}
+
+// convertChecklistsToDescription renders the checklists of a card as task
+// lists for the description.
+func (c *conversion) convertChecklistsToDescription(board *trellosource.Board, card *trello.Card) string {
+	b := &markup.Builder{}
+	for _, checklist := range card.Checklists {
+		b.Raw("\n\n").Element("h2", checklist.Name).Raw("\n\n")
+		b.Open("ul", markup.Attr{Name: "data-type", Value: "taskList"})
+		for _, item := range sortedCheckItems(checklist.CheckItems) {
+			checked := item.State == "complete"
+			c.dropCheckItemDetails(board, card, item, board.CheckItem(item.ID))
+
+			b.Raw("\n")
+			b.Open("li", markup.Attr{Name: "data-checked", Value: strconv.FormatBool(checked)}, markup.Attr{Name: "data-type", Value: "taskItem"})
+			b.Open("label")
+			if checked {
+				b.Open("input", markup.Attr{Name: "type", Value: "checkbox"}, markup.Attr{Name: "checked", Value: "checked"})
+			} else {
+				b.Open("input", markup.Attr{Name: "type", Value: "checkbox"})
+			}
+			b.Open("span").Close("span").Close("label")
+			b.Open("div").Element("p", item.Name).Close("div")
+			b.Close("li")
+		}
+		b.Close("ul")
+	}
+
+	return b.String()
+}
+
+// dropCheckItemDetails records the details of item which are not migrated.
+func (c *conversion) dropCheckItemDetails(board *trellosource.Board, card *trello.Card, item trello.CheckItem, details trellosource.CheckItemDetails) {
+	if details.Due == nil && details.IDMember == "" {
+		return
+	}
+
+	member := details.IDMember
+	for _, m := range card.Members {
+		if m.ID == details.IDMember && m.FullName != "" {
+			member = m.FullName
+		}
+	}
+
+	c.dropped = append(c.dropped, DroppedCheckItem{
+		BoardID: board.ID,
+		Board:   board.Name,
+		CardID:  card.ID,
+		Card:    card.Name,
+		Name:    item.Name,
+		Due:     details.Due,
+		Member:  member,
+	})
+}
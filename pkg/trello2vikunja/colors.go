@@ -0,0 +1,49 @@
+package trello2vikunja
+
+import (
+	"strings"
+
+	"github.com/warrenwingaru/go-trello"
+	"wingaru.me/trello-migrate/pkg/models"
+	"wingaru.me/trello-migrate/pkg/palette"
+)
+
+// convertLabel creates the label for a Trello label or custom field value.
+// If Vikunja already has labels with the same title, the one with the
+// closest color is reused. seed picks a stable color when color is unknown.
+func (c *conversion) convertLabel(title string, color string, seed string) *models.Label {
+	hex := palette.Palette(c.options.Palette).Hex(color, seed)
+
+	var candidates []*models.Label
+	var candidateColors []string
+	for _, label := range c.options.Labels {
+		if strings.EqualFold(label.Title, title) {
+			candidates = append(candidates, label)
+			candidateColors = append(candidateColors, label.HexColor)
+		}
+	}
+
+	if nearest := palette.Nearest(hex, candidateColors); nearest >= 0 {
+		existing := candidates[nearest]
+		return &models.Label{
+			ID:       existing.ID,
+			Title:    existing.Title,
+			HexColor: existing.HexColor,
+		}
+	}
+
+	return &models.Label{
+		Title:    title,
+		HexColor: hex,
+	}
+}
+
+// boardHexColor returns the project color for the background of a board.
+// Boards with a background image have no color.
+func (c *conversion) boardHexColor(board *trello.Board) string {
+	if hex, exists := c.options.Palette[board.Prefs.Background]; exists {
+		return hex
+	}
+
+	return strings.ToLower(strings.TrimPrefix(board.Prefs.BackgroundColor, "#"))
+}
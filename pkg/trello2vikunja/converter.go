@@ -0,0 +1,761 @@
+// Package trello2vikunja converts Trello boards into the Vikunja projects,
+// buckets and tasks they become, without talking to Vikunja.
+package trello2vikunja
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/warrenwingaru/go-trello"
+	"wingaru.me/trello-migrate/pkg/markup"
+	"wingaru.me/trello-migrate/pkg/models"
+	"wingaru.me/trello-migrate/pkg/palette"
+	"wingaru.me/trello-migrate/pkg/trellosource"
+)
+
+// BucketStrategy decides how the tasks of a board are put into buckets.
+type BucketStrategy string
+
+const (
+	// BucketsBySize fills "Archived Tasks" buckets with up to MaxBucketSize
+	// tasks each, in the order of the lists.
+	BucketsBySize BucketStrategy = "size"
+	// BucketsByList creates a bucket for every list, split into several
+	// when a list has more than MaxBucketSize cards.
+	BucketsByList BucketStrategy = "list"
+)
+
+// DefaultMaxBucketSize is the MaxBucketSize used when none is set.
+const DefaultMaxBucketSize = 200
+
+// Names of the log fields, the same the CLI uses.
+const (
+	logBoardID = "board_id"
+	logCardID  = "card_id"
+)
+
+// Options configure a Converter. The zero value converts all boards with a
+// Vikunja project into size based buckets, with checklists in the
+// description and without history.
+type Options struct {
+	BucketStrategy BucketStrategy
+	MaxBucketSize  int
+	ChecklistMode  ChecklistMode
+	HistoryMode    HistoryMode
+	CustomFields   CustomFieldConfig
+	// Palette maps Trello color names to Vikunja hex colors, without the #.
+	// The built-in palette is used when nil.
+	Palette map[string]string
+	// Sanitizer cleans up the html of descriptions and comments, rendered
+	// from Trello markdown. The default allowlist is used when nil.
+	Sanitizer Sanitizer
+	// Labels are the labels which already exist in Vikunja. They are reused
+	// instead of creating new labels with the same title.
+	Labels []*models.Label
+	// CardLinkRelation is the relation kind between tasks whose cards were
+	// linked through an attachment, models.RelationKindRelated when empty.
+	CardLinkRelation models.RelationKind
+
+	// Boards are the names of the boards to convert. All boards with a
+	// Vikunja project are converted when empty.
+	Boards []string
+	// CardFilter, when set, skips the cards it returns false for.
+	CardFilter func(board *trellosource.Board, list *trello.List, card *trello.Card) bool
+
+	// SkipDownloads only lists attachments instead of reading their files.
+	// Their sizes are only checked when the source is a trellosource.Sizer.
+	SkipDownloads bool
+	// MaxAttachmentSize is the largest file Vikunja accepts, in bytes.
+	// Larger uploads are handled by OversizePolicy. There is no limit when
+	// it is 0.
+	MaxAttachmentSize int64
+	// OversizePolicy is OversizeLink when empty.
+	OversizePolicy OversizePolicy
+	// HTTPClient downloads the cover images, http.DefaultClient when nil.
+	HTTPClient *http.Client
+	// Logger gets an entry for every board and card converted. It discards
+	// them when nil.
+	Logger logrus.FieldLogger
+	// Progress shows the boards, cards and attachments converted. No bars
+	// are shown when nil.
+	Progress Progress
+}
+
+// Sanitizer removes unsafe html.
+type Sanitizer interface {
+	Sanitize(html string) string
+}
+
+// ProgressLevel is the nesting of a progress bar: boards contain cards,
+// cards contain attachments.
+type ProgressLevel int
+
+const (
+	ProgressBoards ProgressLevel = iota
+	ProgressCards
+	ProgressAttachments
+)
+
+// Progress shows how far a conversion got.
+type Progress interface {
+	// Add starts a bar named name for total items.
+	Add(name string, total int, level ProgressLevel) ProgressBar
+}
+
+// ProgressBar is a bar of a Progress.
+type ProgressBar interface {
+	// Increment marks one more item as done.
+	Increment()
+	// Done completes the bar, even if not all items were marked as done.
+	Done()
+}
+
+// noProgress is the Progress of converters without one.
+type noProgress struct{}
+
+func (noProgress) Add(string, int, ProgressLevel) ProgressBar { return noProgress{} }
+func (noProgress) Increment()                                 {}
+func (noProgress) Done()                                      {}
+
+// Converter converts Trello boards into Vikunja projects.
+type Converter struct {
+	source  trellosource.Source
+	options Options
+}
+
+// NewConverter creates a Converter reading the files of attachments from
+// source.
+func NewConverter(source trellosource.Source, options Options) *Converter {
+	if options.BucketStrategy == "" {
+		options.BucketStrategy = BucketsBySize
+	}
+	if options.MaxBucketSize <= 0 {
+		options.MaxBucketSize = DefaultMaxBucketSize
+	}
+	if options.ChecklistMode == "" {
+		options.ChecklistMode = ChecklistsDescription
+	}
+	if options.Palette == nil {
+		options.Palette = palette.Default()
+	}
+	if options.Sanitizer == nil {
+		options.Sanitizer = markup.NewSanitizer(markup.DefaultAllowlist())
+	}
+	if options.OversizePolicy == "" {
+		options.OversizePolicy = OversizeLink
+	}
+	if options.CardLinkRelation == "" {
+		options.CardLinkRelation = models.RelationKindRelated
+	}
+	if options.HTTPClient == nil {
+		options.HTTPClient = http.DefaultClient
+	}
+	if options.Logger == nil {
+		options.Logger = discardLogger
+	}
+	if options.Progress == nil {
+		options.Progress = noProgress{}
+	}
+
+	return &Converter{source: source, options: options}
+}
+
+// discardLogger is the Logger of converters without one.
+var discardLogger = &logrus.Logger{
+	Out:       io.Discard,
+	Formatter: new(logrus.TextFormatter),
+	Hooks:     make(logrus.LevelHooks),
+	Level:     logrus.PanicLevel,
+}
+
+// Plan is what a conversion would create in Vikunja, ready to be uploaded.
+type Plan struct {
+	Projects []*models.ProjectWithTasksAndBuckets
+	// CardLinkRelation is the relation kind between tasks whose cards were
+	// linked, from Options.CardLinkRelation.
+	CardLinkRelation models.RelationKind
+	// MissingBoards are the Options.Boards which were not converted because
+	// the boards or their projects don't exist.
+	MissingBoards []string
+	// Oversized are the attachments larger than Options.MaxAttachmentSize,
+	// which were linked or zipped.
+	Oversized []OversizedAttachment
+	// LinkedAttachments are the uploaded attachments and cover images whose
+	// files couldn't be read, which were linked instead.
+	LinkedAttachments []LinkedAttachment
+	// DroppedCheckItems are the checklist items whose due date or member
+	// couldn't be migrated.
+	DroppedCheckItems []DroppedCheckItem
+}
+
+// LinkedAttachment is an uploaded attachment which was linked in the task
+// description because its file couldn't be read.
+type LinkedAttachment struct {
+	BoardID string
+	Board   string
+	CardID  string
+	Card    string
+	Name    string
+	URL     string
+	// Err is why the file couldn't be read.
+	Err error
+}
+
+// Reason tells why the attachment was linked, for reports.
+func (a LinkedAttachment) Reason() string {
+	return fmt.Sprintf("the file could not be read: %v; linked instead", a.Err)
+}
+
+// DroppedCheckItem is a checklist item whose due date or member was left
+// out. Task list items in descriptions have neither, and Trello members are
+// not mapped to Vikunja users, so subtasks are never assigned.
+type DroppedCheckItem struct {
+	BoardID string
+	Board   string
+	CardID  string
+	Card    string
+	Name    string
+	// Due is the due date, when it was dropped.
+	Due *time.Time
+	// Member is the name of the member the item was assigned to, or the id
+	// of members who are not on the card.
+	Member string
+}
+
+// Reason tells what was dropped, for reports.
+func (i DroppedCheckItem) Reason() string {
+	var dropped []string
+	if i.Due != nil {
+		dropped = append(dropped, "due date "+i.Due.Format(time.DateOnly))
+	}
+	if i.Member != "" {
+		dropped = append(dropped, "member "+i.Member)
+	}
+
+	return "the checklist item's " + strings.Join(dropped, " and ") + " is not migrated"
+}
+
+// ProjectSummary counts what a Plan creates in one project.
+type ProjectSummary struct {
+	Title       string
+	ProjectID   int64
+	Buckets     int
+	Tasks       int
+	Comments    int
+	Attachments int
+	Labels      int
+	Subtasks    int
+	CardLinks   int
+}
+
+// Summary counts what the plan creates in each project.
+func (p *Plan) Summary() []ProjectSummary {
+	summaries := make([]ProjectSummary, 0, len(p.Projects))
+	for _, project := range p.Projects {
+		summary := ProjectSummary{Title: project.Title, ProjectID: project.ID, Buckets: len(project.Buckets)}
+		for _, bucket := range project.Buckets {
+			for _, task := range bucket.TasksWithComments {
+				summary.Tasks++
+				summary.Comments += len(task.Comments)
+				summary.Attachments += len(task.Attachments)
+				summary.Labels += len(task.Labels)
+				summary.Subtasks += len(task.Subtasks)
+				summary.CardLinks += len(task.TrelloCardLinks)
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries
+}
+
+func (p *Plan) hasProject(title string) bool {
+	for _, project := range p.Projects {
+		if project.Title == title {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ConvertSource loads all boards of the source and converts them.
+func (c *Converter) ConvertSource(projects map[string]models.Project) (*Plan, error) {
+	boards, err := trellosource.Load(c.source)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Convert(boards, projects)
+}
+
+// Convert converts boards into the Vikunja projects of the same name in
+// projects, which must already exist. Projects of Vikunja before 0.24 have no
+// views, their buckets belong to the project itself. Boards without a
+// project are skipped.
+func (c *Converter) Convert(boards []*trellosource.Board, projects map[string]models.Project) (*Plan, error) {
+	conv := &conversion{
+		Converter: c,
+		renderer:  markup.NewRenderer(c.options.Sanitizer.Sanitize, getCardNames(boards)),
+	}
+
+	c.options.Logger.Debugf("Converting %d boards to vikunja projects", len(boards))
+	total := len(c.options.Boards)
+	if total == 0 {
+		total = len(boards)
+	}
+	boardBar := c.options.Progress.Add("converting boards", total, ProgressBoards)
+	defer boardBar.Done()
+
+	plan := &Plan{CardLinkRelation: c.options.CardLinkRelation}
+	for _, board := range boards {
+		projectFromData, found := projects[board.Name]
+		if !found || !c.includesBoard(board.Name) {
+			continue
+		}
+
+		project, err := conv.convertBoard(board, projectFromData)
+		if err != nil {
+			return nil, err
+		}
+		boardBar.Increment()
+
+		plan.Projects = append(plan.Projects, project)
+	}
+
+	for _, name := range c.options.Boards {
+		if !plan.hasProject(name) {
+			plan.MissingBoards = append(plan.MissingBoards, name)
+		}
+	}
+
+	plan.Oversized = conv.oversized
+	plan.LinkedAttachments = conv.linked
+	plan.DroppedCheckItems = conv.dropped
+	if c.options.OversizePolicy == OversizeFail && len(plan.Oversized) > 0 {
+		return nil, &OversizeError{Attachments: plan.Oversized}
+	}
+
+	return plan, nil
+}
+
+func (c *Converter) includesBoard(name string) bool {
+	if len(c.options.Boards) == 0 {
+		return true
+	}
+
+	for _, board := range c.options.Boards {
+		if board == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// conversion holds the state of a single call to Convert.
+type conversion struct {
+	*Converter
+	// renderer knows the names of all converted cards to show links to them
+	// by name.
+	renderer *markup.Renderer
+	// oversized collects the attachments larger than MaxAttachmentSize.
+	oversized []OversizedAttachment
+	// linked collects the attachments whose files couldn't be read.
+	linked []LinkedAttachment
+	// dropped collects the checklist items with a due date or member which
+	// was left out.
+	dropped []DroppedCheckItem
+}
+
+func (c *conversion) convertBoard(board *trellosource.Board, projectFromData models.Project) (*models.ProjectWithTasksAndBuckets, error) {
+	project := &models.ProjectWithTasksAndBuckets{
+		Project: models.Project{
+			ID:          projectFromData.ID,
+			Title:       board.Name,
+			Description: board.Desc,
+			HexColor:    c.boardHexColor(board.Board),
+		},
+		TrelloBoardID:        board.ID,
+		TrelloBoardShortLink: boardShortLink(board.ShortURL),
+		TrelloListCount:      len(board.Lists),
+	}
+	views := projectViewsOf(projectFromData)
+	for _, view := range views {
+		for _, title := range positionedViewTitles {
+			if view.Title == title {
+				project.Views = append(project.Views, view)
+			}
+		}
+	}
+	cards := 0
+	for _, l := range board.Lists {
+		cards += len(l.Cards)
+	}
+	cardBar := c.options.Progress.Add(board.Name, cards, ProgressCards)
+	defer cardBar.Done()
+	boardLogger := c.options.Logger.WithField(logBoardID, board.ID)
+	boardLogger.Infof("Converting board %s", board.Name)
+
+	// create bucket for each view or maybe for kanban only
+	for _, view := range views {
+		if view.Title != "Kanban" {
+			continue
+		}
+
+		buckets := &bucketBuilder{project: project, view: view, maxSize: c.options.MaxBucketSize}
+		position := 0
+
+		// Create tasks with the new bucket, keeping the order of lists and cards
+		for _, l := range sortedLists(board.Lists) {
+			if c.options.BucketStrategy == BucketsByList {
+				buckets.startList(l.Name)
+			}
+
+			boardLogger.Debugf("Converting %d cards of list %s", len(l.Cards), l.Name)
+			for _, card := range sortedCards(l.Cards) {
+				cardBar.Increment()
+				if c.options.CardFilter != nil && !c.options.CardFilter(board, l, card) {
+					boardLogger.WithField(logCardID, card.ID).Debugf("Filtered out card %s", card.Name)
+					continue
+				}
+
+				task, err := c.convertCard(board, card, projectFromData.ID, position)
+				if err != nil {
+					return nil, err
+				}
+				position++
+
+				buckets.add(task)
+			}
+		}
+
+		buckets.flush()
+	}
+	boardLogger.Debug("Converted all cards")
+
+	return project, nil
+}
+
+// projectViewsOf returns the views of a project. Projects exported before
+// Vikunja had views get a single kanban view with the id 0, which the
+// vikunja client maps to the buckets and positions of the project itself.
+func projectViewsOf(project models.Project) []*models.ProjectView {
+	if len(project.Views) > 0 {
+		return project.Views
+	}
+
+	return []*models.ProjectView{{Title: "Kanban", ProjectID: project.ID, ViewKind: "kanban"}}
+}
+
+// bucketBuilder fills the buckets of a project view with up to maxSize
+// tasks each.
+type bucketBuilder struct {
+	project *models.ProjectWithTasksAndBuckets
+	view    *models.ProjectView
+	maxSize int
+
+	// list is the name of the list the buckets are for, empty for the
+	// "Archived Tasks" buckets.
+	list      string
+	listCount int
+	tasks     []*models.TaskWithComments
+}
+
+// startList makes the following tasks go into buckets of the list name.
+func (b *bucketBuilder) startList(name string) {
+	b.flush()
+	b.list = name
+	b.listCount = 0
+}
+
+func (b *bucketBuilder) add(task *models.TaskWithComments) {
+	b.tasks = append(b.tasks, task)
+
+	// Hard limits to tasks size to maxSize
+	if len(b.tasks) >= b.maxSize {
+		b.flush()
+	}
+}
+
+// flush puts the collected tasks into a new bucket.
+func (b *bucketBuilder) flush() {
+	if len(b.tasks) == 0 {
+		return
+	}
+
+	title := fmt.Sprintf("Archived Tasks %d", len(b.project.Buckets)+1)
+	if b.list != "" {
+		b.listCount++
+		title = b.list
+		if b.listCount > 1 {
+			title = fmt.Sprintf("%s %d", b.list, b.listCount)
+		}
+	}
+
+	b.project.Buckets = append(b.project.Buckets, &models.Bucket{
+		ProjectID:         b.project.ID,
+		ProjectViewID:     b.view.ID,
+		Title:             title,
+		Position:          taskPosition(len(b.project.Buckets)),
+		TasksWithComments: b.tasks,
+	})
+	b.tasks = nil
+}
+
+func (c *conversion) convertCard(board *trellosource.Board, card *trello.Card, projectID int64, position int) (*models.TaskWithComments, error) {
+	cardLogger := c.options.Logger.WithFields(logrus.Fields{logBoardID: board.ID, logCardID: card.ID})
+	cardLogger.Debugf("Converting card %s", card.Name)
+
+	task := &models.TaskWithComments{
+		Task: models.Task{
+			Title:     card.Name,
+			ProjectID: projectID,
+			Position:  taskPosition(position),
+		},
+		TrelloCardID:        card.ID,
+		TrelloCardShortLink: card.ShortLink,
+	}
+
+	task.Description, _ = c.renderer.Render(card.Desc)
+
+	if c.options.ChecklistMode == ChecklistsSubtasks {
+		c.convertChecklistsToSubtasks(task, board, card)
+	} else {
+		task.Description += c.convertChecklistsToDescription(board, card)
+	}
+	if len(card.Checklists) > 0 {
+		cardLogger.Debugf("Converted %d checklists", len(card.Checklists))
+	}
+
+	c.convertCustomFields(task, card, board.CustomFields)
+
+	// Labels
+	for _, label := range card.Labels {
+		task.Labels = append(task.Labels, c.convertLabel(label.Name, label.Color, label.ID))
+
+		cardLogger.WithField("label_id", label.ID).Trace("Converted label")
+
+	}
+	var attachmentBar ProgressBar = noProgress{}
+	if len(card.Attachments) > 0 {
+		attachmentBar = c.options.Progress.Add("attachments of "+card.Name, len(card.Attachments), ProgressAttachments)
+		cardLogger.Debugf("Downloading %d attachments", len(card.Attachments))
+	}
+	defer attachmentBar.Done()
+
+	for _, attachment := range card.Attachments {
+		attachmentBar.Increment()
+		if attachment.IsUpload {
+			cardLogger.WithField("attachment_id", attachment.ID).Debug("Downloading attachment")
+
+			buf, err := c.fetchUpload(attachment)
+			if err != nil {
+				cardLogger.WithField("attachment_id", attachment.ID).WithError(err).Warnf("Linking attachment %s instead of uploading it", attachment.Name)
+				task.Description += c.linkAttachment(board, card, attachment.Name, attachment.URL, err)
+				continue
+			}
+
+			size, known := c.attachmentSize(attachment, buf)
+			if known && c.options.MaxAttachmentSize > 0 && size > c.options.MaxAttachmentSize {
+				parts, err := c.convertOversized(board, card, attachment, buf.Bytes(), size)
+				if err != nil {
+					return nil, err
+				}
+				if len(parts) == 0 {
+					task.Description += AttachmentLink(attachment.Name, attachment.URL)
+				}
+				cardLogger.WithField("attachment_id", attachment.ID).Warnf("Attachment %s is larger than Vikunja accepts", attachment.Name)
+				task.Attachments = append(task.Attachments, parts...)
+				continue
+			}
+
+			vikunjaAttachment := &models.TaskAttachment{
+				File: &models.File{
+					Name:        attachment.Name,
+					Mime:        attachment.MimeType,
+					Size:        uint64(buf.Len()),
+					FileContent: buf.Bytes(),
+				},
+			}
+
+			if card.IDAttachmentCover != "" && card.IDAttachmentCover == attachment.ID {
+				vikunjaAttachment.ID = 42
+				task.CoverImageAttachmentID = 42
+			}
+			task.Attachments = append(task.Attachments, vikunjaAttachment)
+			cardLogger.WithField("attachment_id", attachment.ID).Debug("Downloaded attachment")
+			continue
+		}
+
+		if shortLink, ok := markup.CardShortLink(attachment.URL); ok {
+			task.TrelloCardLinks = append(task.TrelloCardLinks, &models.TrelloCardLink{
+				ShortLink: shortLink,
+				Name:      attachment.Name,
+				URL:       attachment.URL,
+			})
+			continue
+		}
+
+		task.Description += AttachmentLink(attachment.Name, attachment.URL)
+	}
+
+	// When the cover image was set manually, we need to add it as an attachment
+	if card.ManualCoverAttachment && len(card.Cover.Scaled) > 0 {
+
+		cover := card.Cover.Scaled[len(card.Cover.Scaled)-1]
+
+		name := cover.ID + ".jpg"
+		buf, err := c.fetchFile(cover.URL)
+		if err != nil {
+			cardLogger.WithError(err).Warnf("Linking the cover image %s instead of uploading it", name)
+			task.Description += c.linkAttachment(board, card, name, cover.URL, err)
+		} else {
+			coverAttachment := &models.TaskAttachment{
+				ID: 43,
+				File: &models.File{
+					Name:        name,
+					Mime:        "image/jpg", // Seems to always return jpg
+					Size:        uint64(buf.Len()),
+					FileContent: buf.Bytes(),
+				},
+			}
+
+			task.Attachments = append(task.Attachments, coverAttachment)
+			task.CoverImageAttachmentID = coverAttachment.ID
+		}
+	}
+
+	for _, action := range card.Actions {
+		if action.DidCommentCard() {
+			if task.Comments == nil {
+				task.Comments = []*models.TaskComment{}
+			}
+
+			comment := &models.TaskComment{
+				Comment: action.Data.Text,
+				Created: action.Date,
+				Updated: action.Date,
+				TaskID:  task.ID,
+			}
+
+			comment.Comment = "*" + actionActorName(action) + "*:\n\n" + comment.Comment
+
+			comment.Comment, _ = c.renderer.Render(comment.Comment)
+			task.Comments = append(task.Comments, comment)
+		}
+	}
+
+	addCardHistory(task, card, c.options.HistoryMode)
+
+	// The rendered markdown is sanitized already, but the checklists,
+	// custom fields and attachment links added to it come from Trello too.
+	task.Description = c.options.Sanitizer.Sanitize(task.Description)
+
+	return task, nil
+}
+
+// linkAttachment records the attachment name of card, whose file couldn't
+// be read because of err, and returns the link to it for the description.
+func (c *conversion) linkAttachment(board *trellosource.Board, card *trello.Card, name string, url string, err error) string {
+	c.linked = append(c.linked, LinkedAttachment{
+		BoardID: board.ID,
+		Board:   board.Name,
+		CardID:  card.ID,
+		Card:    card.Name,
+		Name:    name,
+		URL:     url,
+		Err:     err,
+	})
+
+	return AttachmentLink(name, url)
+}
+
+// fetchFile downloads a public file, unless downloads are skipped.
+func (c *conversion) fetchFile(url string) (*bytes.Buffer, error) {
+	if c.options.SkipDownloads {
+		return &bytes.Buffer{}, nil
+	}
+
+	started := time.Now()
+	resp, err := c.options.HTTPClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	buf := &bytes.Buffer{}
+	_, err = buf.ReadFrom(resp.Body)
+
+	c.options.Logger.WithFields(logrus.Fields{
+		"url":         url,
+		"http_status": resp.StatusCode,
+		"duration":    time.Since(started).Round(time.Millisecond).String(),
+		"bytes":       buf.Len(),
+	}).Debug("Downloaded file")
+
+	if resp.StatusCode > 299 {
+		return nil, fmt.Errorf("downloading %s: %s", url, resp.Status)
+	}
+
+	return buf, err
+}
+
+// fetchUpload reads the file of an uploaded attachment from the source,
+// unless downloads are skipped.
+func (c *conversion) fetchUpload(attachment *trello.Attachment) (*bytes.Buffer, error) {
+	if c.options.SkipDownloads {
+		return &bytes.Buffer{}, nil
+	}
+
+	content, err := c.source.Attachment(attachment)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	buf := &bytes.Buffer{}
+	_, err = buf.ReadFrom(content)
+	return buf, err
+}
+
+// getCardNames maps the short link of every exported card to its name.
+func getCardNames(boards []*trellosource.Board) map[string]string {
+	names := make(map[string]string)
+	for _, board := range boards {
+		for _, l := range board.Lists {
+			for _, card := range l.Cards {
+				names[card.ShortLink] = card.Name
+			}
+		}
+	}
+
+	return names
+}
+
+// AttachmentLink renders a link attachment as a paragraph of the description.
+func AttachmentLink(name string, url string) string {
+	link := &markup.Builder{}
+	link.Open("p").Element("a", name, markup.Attr{Name: "href", Value: url}).Close("p")
+
+	return link.String() + "\n"
+}
+
+// boardURLPattern matches the url of a Trello board and its short link.
+var boardURLPattern = regexp.MustCompile(`https?://trello\.com/b/([A-Za-z0-9]+)`)
+
+// boardShortLink extracts the short link from the url of a Trello board.
+func boardShortLink(url string) string {
+	match := boardURLPattern.FindStringSubmatch(url)
+	if match == nil {
+		return ""
+	}
+
+	return match[1]
+}
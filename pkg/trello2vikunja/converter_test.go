@@ -0,0 +1,300 @@
+package trello2vikunja
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/warrenwingaru/go-trello"
+	"wingaru.me/trello-migrate/pkg/markup"
+	"wingaru.me/trello-migrate/pkg/models"
+	"wingaru.me/trello-migrate/pkg/trellosource"
+)
+
+// convertTestCard converts card of a board without custom fields.
+func convertTestCard(t *testing.T, options Options, card *trello.Card) string {
+	t.Helper()
+
+	converter := NewConverter(nil, options)
+	conv := &conversion{
+		Converter: converter,
+		renderer:  markup.NewRenderer(converter.options.Sanitizer.Sanitize, nil),
+	}
+	board := &trellosource.Board{Board: &trello.Board{ID: "board1", Name: "Board"}}
+	task, err := conv.convertCard(board, card, 1, 0)
+	if err != nil {
+		t.Fatalf("convertCard: %v", err)
+	}
+	if task.Title != card.Name {
+		t.Errorf("the title is %q, want the name of the card %q unchanged, Vikunja shows titles as text", task.Title, card.Name)
+	}
+
+	return task.Description
+}
+
+func TestConvertCardEscapesHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		card    *trello.Card
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "title",
+			card:    &trello.Card{ID: "card1", Name: `<script>alert("title")</script>`},
+			notWant: []string{"<script"},
+		},
+		{
+			name: "description",
+			card: &trello.Card{
+				ID:   "card1",
+				Name: "Card",
+				Desc: "<script>alert(1)</script>\n\n<img src=\"https://example.com/a.png\" onerror=\"alert(1)\">\n\n[click](javascript:alert(1)) <b style=\"color:red\">raw</b>",
+			},
+			want:    []string{"<a>click</a>", "raw"},
+			notWant: []string{"<script", "<img", "onerror", "javascript:", "style="},
+		},
+		{
+			name: "checklist names",
+			card: &trello.Card{
+				ID:   "card1",
+				Name: "Card",
+				Checklists: []*trello.Checklist{{
+					Name:       `<b>"Steps"</b>`,
+					CheckItems: []trello.CheckItem{{Name: `<script>alert("item")</script>`, State: "complete"}},
+				}},
+			},
+			want:    []string{`<h2>&lt;b&gt;&#34;Steps&#34;&lt;/b&gt;</h2>`, `&lt;script&gt;alert(&#34;item&#34;)&lt;/script&gt;`, `data-checked="true"`},
+			notWant: []string{"<script", "<b>"},
+		},
+		{
+			name: "attachment names and urls",
+			card: &trello.Card{
+				ID:   "card1",
+				Name: "Card",
+				Attachments: []*trello.Attachment{
+					{ID: "a1", Name: `<img src=x onerror="alert(1)">`, URL: `https://example.com/?q="><script>alert(1)</script>`},
+					{ID: "a2", Name: "payload", URL: "javascript:alert(1)"},
+					{ID: "a3", Name: "data", URL: "data:text/html;base64,PHNjcmlwdD4="},
+				},
+			},
+			want:    []string{`&lt;img src=x onerror=&#34;alert(1)&#34;&gt;`, `href="https://example.com/?q=&#34;&gt;&lt;script&gt;alert(1)&lt;/script&gt;"`, "<a>payload</a>", "<a>data</a>"},
+			notWant: []string{"<script", "<img", "javascript:", "data:text"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			description := convertTestCard(t, Options{}, test.card)
+			for _, want := range test.want {
+				if !strings.Contains(description, want) {
+					t.Errorf("the description has no %s:\n%s", want, description)
+				}
+			}
+			for _, notWant := range test.notWant {
+				if strings.Contains(description, notWant) {
+					t.Errorf("the description has %s:\n%s", notWant, description)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertCardSubtaskTitles(t *testing.T) {
+	card := &trello.Card{
+		ID:   "card1",
+		Name: "Card",
+		Checklists: []*trello.Checklist{{
+			Name:       "Steps",
+			CheckItems: []trello.CheckItem{{Name: `<script>alert("item")</script>`}},
+		}},
+	}
+
+	converter := NewConverter(nil, Options{ChecklistMode: ChecklistsSubtasks})
+	conv := &conversion{Converter: converter, renderer: markup.NewRenderer(converter.options.Sanitizer.Sanitize, nil)}
+	task, err := conv.convertCard(&trellosource.Board{Board: &trello.Board{ID: "board1"}}, card, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(task.Subtasks) != 1 || task.Subtasks[0].Title != card.Checklists[0].CheckItems[0].Name {
+		t.Errorf("the subtasks are %+v, want one titled %q as text", task.Subtasks, card.Checklists[0].CheckItems[0].Name)
+	}
+	if strings.Contains(task.Description, "<script") {
+		t.Errorf("the description has a script:\n%s", task.Description)
+	}
+}
+
+func TestConvertCardAllowlist(t *testing.T) {
+	card := &trello.Card{
+		ID:   "card1",
+		Name: "Card",
+		Desc: "**bold** and [a link](http://example.com)",
+		Checklists: []*trello.Checklist{{
+			Name:       "Steps",
+			CheckItems: []trello.CheckItem{{Name: "first"}},
+		}},
+		Attachments: []*trello.Attachment{{ID: "a1", Name: "secure", URL: "https://example.com/file"}},
+	}
+	allowlist := &markup.Allowlist{
+		Elements:   map[string][]string{"a": {"href"}, "p": nil},
+		URLSchemes: []string{"https"},
+	}
+
+	description := convertTestCard(t, Options{Sanitizer: markup.NewSanitizer(allowlist)}, card)
+	for _, want := range []string{"<p>bold and <a>a link</a></p>", `<a href="https://example.com/file">secure</a>`, "Steps", "first"} {
+		if !strings.Contains(description, want) {
+			t.Errorf("the description has no %s:\n%s", want, description)
+		}
+	}
+	for _, notWant := range []string{"<strong>", "<h2>", "<ul", "<li", "<input", "http://example.com"} {
+		if strings.Contains(description, notWant) {
+			t.Errorf("the description has %s, which the allowlist doesn't allow:\n%s", notWant, description)
+		}
+	}
+}
+
+// unreadableSource fails to read any attachment.
+type unreadableSource struct {
+	trellosource.Source
+}
+
+func (unreadableSource) Attachment(attachment *trello.Attachment) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("%s: %w", attachment.Name, trellosource.ErrAttachmentUnavailable)
+}
+
+func TestConvertLinksUnreadableAttachments(t *testing.T) {
+	card := &trello.Card{
+		ID:   "card1",
+		Name: "Card",
+		Attachments: []*trello.Attachment{
+			{ID: "a1", Name: "report.pdf", URL: "https://trello.com/1/cards/card1/attachments/a1/download/report.pdf", IsUpload: true},
+			{ID: "a2", Name: "website", URL: "https://example.com"},
+		},
+	}
+	board := &trellosource.Board{Board: &trello.Board{
+		ID:    "board1",
+		Name:  "Board",
+		Lists: []*trello.List{{ID: "list1", Name: "Done", Closed: true, Cards: []*trello.Card{card}}},
+	}}
+	projects := map[string]models.Project{"Board": {ID: 1, Title: "Board", Views: []*models.ProjectView{{ID: 4, Title: "Kanban"}}}}
+
+	plan, err := NewConverter(unreadableSource{}, Options{}).Convert([]*trellosource.Board{board}, projects)
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+
+	if len(plan.LinkedAttachments) != 1 {
+		t.Fatalf("the plan links %d attachments, want the unreadable upload only: %+v", len(plan.LinkedAttachments), plan.LinkedAttachments)
+	}
+	linked := plan.LinkedAttachments[0]
+	if linked.BoardID != "board1" || linked.CardID != "card1" || linked.Name != "report.pdf" || !errors.Is(linked.Err, trellosource.ErrAttachmentUnavailable) {
+		t.Errorf("the linked attachment is %+v", linked)
+	}
+
+	task := plan.Projects[0].Buckets[0].TasksWithComments[0]
+	if len(task.Attachments) != 0 {
+		t.Errorf("the task has %d attachments, want none", len(task.Attachments))
+	}
+	if !strings.Contains(task.Description, `<a href="https://trello.com/1/cards/card1/attachments/a1/download/report.pdf">report.pdf</a>`) {
+		t.Errorf("the description doesn't link the attachment:\n%s", task.Description)
+	}
+}
+
+func TestFetchFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cover.jpg" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("image"))
+	}))
+	defer server.Close()
+
+	conv := &conversion{Converter: NewConverter(nil, Options{HTTPClient: server.Client()})}
+	buf, err := conv.fetchFile(server.URL + "/cover.jpg")
+	if err != nil {
+		t.Fatalf("fetchFile: %v", err)
+	}
+	if buf.String() != "image" {
+		t.Errorf("downloaded %q, want %q", buf.String(), "image")
+	}
+
+	buf, err = conv.fetchFile(server.URL + "/missing.jpg")
+	if err == nil {
+		t.Errorf("downloading a missing file returned %q and no error", buf.String())
+	}
+}
+
+func TestConvertChecklistItemDetails(t *testing.T) {
+	due := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	newCard := func() *trello.Card {
+		return &trello.Card{
+			ID:      "card1",
+			Name:    "Card",
+			Members: []*trello.Member{{ID: "member1", FullName: "Alice"}},
+			Checklists: []*trello.Checklist{{
+				Name: "Steps",
+				CheckItems: []trello.CheckItem{
+					{ID: "item2", Name: "second", Pos: 32768},
+					{ID: "item1", Name: "first", Pos: 16384, State: "complete"},
+					{ID: "item3", Name: "third", Pos: 49152},
+				},
+			}},
+		}
+	}
+	board := &trellosource.Board{
+		Board: &trello.Board{ID: "board1", Name: "Board"},
+		CheckItems: map[string]trellosource.CheckItemDetails{
+			"item1": {Due: &due},
+			"item2": {IDMember: "member1"},
+		},
+	}
+
+	converter := NewConverter(nil, Options{ChecklistMode: ChecklistsSubtasks})
+	conv := &conversion{Converter: converter, renderer: markup.NewRenderer(converter.options.Sanitizer.Sanitize, nil)}
+	task, err := conv.convertCard(board, newCard(), 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(task.Subtasks) != 3 {
+		t.Fatalf("the task has %d subtasks, want 3", len(task.Subtasks))
+	}
+	previous := task.Position
+	for i, want := range []string{"first", "second", "third"} {
+		subtask := task.Subtasks[i]
+		if subtask.Title != want {
+			t.Errorf("subtask %d is %q, want %q", i, subtask.Title, want)
+		}
+		if subtask.Position <= previous || subtask.Position >= taskPosition(1) {
+			t.Errorf("subtask %s is at %v, want between %v and the next task at %v", subtask.Title, subtask.Position, previous, taskPosition(1))
+		}
+		previous = subtask.Position
+	}
+	if !task.Subtasks[0].DueDate.Equal(due) {
+		t.Errorf("the first subtask is due %v, want %v", task.Subtasks[0].DueDate, due)
+	}
+	if len(conv.dropped) != 1 || conv.dropped[0].Name != "second" || conv.dropped[0].Member != "Alice" || conv.dropped[0].Due != nil {
+		t.Errorf("dropped %+v, want the member of the second item only", conv.dropped)
+	}
+
+	converter = NewConverter(nil, Options{})
+	conv = &conversion{Converter: converter, renderer: markup.NewRenderer(converter.options.Sanitizer.Sanitize, nil)}
+	task, err = conv.convertCard(board, newCard(), 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first, second := strings.Index(task.Description, "first"), strings.Index(task.Description, "second"); first < 0 || second < first {
+		t.Errorf("the items are not in their Trello order:\n%s", task.Description)
+	}
+	if len(conv.dropped) != 2 {
+		t.Fatalf("dropped %+v, want the due date of the first and the member of the second item", conv.dropped)
+	}
+	if reason := conv.dropped[0].Reason(); reason != "the checklist item's due date 2024-05-01 is not migrated" {
+		t.Errorf("the reason is %q", reason)
+	}
+}
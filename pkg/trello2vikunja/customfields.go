@@ -0,0 +1,237 @@
+package trello2vikunja
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/warrenwingaru/go-trello"
+	"wingaru.me/trello-migrate/pkg/markup"
+	"wingaru.me/trello-migrate/pkg/models"
+)
+
+// Targets a Trello custom field can be mapped to.
+const (
+	customFieldTargetLabel       = "label"
+	customFieldTargetDueDate     = "due_date"
+	customFieldTargetStartDate   = "start_date"
+	customFieldTargetPriority    = "priority"
+	customFieldTargetDescription = "description"
+	customFieldTargetIgnore      = "ignore"
+)
+
+// customFieldTargets are all targets, for the error about unknown ones.
+var customFieldTargets = []string{
+	customFieldTargetLabel,
+	customFieldTargetDueDate,
+	customFieldTargetStartDate,
+	customFieldTargetPriority,
+	customFieldTargetDescription,
+	customFieldTargetIgnore,
+}
+
+// maxPriority is the highest priority Vikunja knows ("DO NOW").
+const maxPriority = 5
+
+// CustomFieldConfig maps Trello custom field names to the task property they
+// should end up in. Fields without an entry use defaultCustomFieldTarget.
+type CustomFieldConfig map[string]string
+
+// ReadCustomFieldConfig reads a CustomFieldConfig from a json file. An empty
+// filename gives an empty config. Unknown targets are an error, so a typo
+// doesn't silently fall back to the default target.
+func ReadCustomFieldConfig(filename string) (CustomFieldConfig, error) {
+	config := CustomFieldConfig{}
+	if filename == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(config))
+	for name := range config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !validCustomFieldTarget(config[name]) {
+			return nil, fmt.Errorf("%s: unknown target %q for custom field %s, use one of %v", filename, config[name], name, customFieldTargets)
+		}
+	}
+
+	return config, nil
+}
+
+func validCustomFieldTarget(target string) bool {
+	for _, valid := range customFieldTargets {
+		if target == valid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// target returns where the values of field should go.
+func (c CustomFieldConfig) target(field *trello.CustomField) string {
+	if target, exists := c[field.Name]; exists {
+		return target
+	}
+
+	return defaultCustomFieldTarget(field)
+}
+
+// defaultCustomFieldTarget turns dropdown and checkbox fields into labels and
+// renders everything else into the description.
+func defaultCustomFieldTarget(field *trello.CustomField) string {
+	switch field.Type {
+	case "list", "checkbox":
+		return customFieldTargetLabel
+	default:
+		return customFieldTargetDescription
+	}
+}
+
+// convertCustomFields applies the custom field values of a card to task.
+func (c *conversion) convertCustomFields(task *models.TaskWithComments, card *trello.Card, fields []*trello.CustomField) {
+	if len(card.CustomFieldItems) == 0 {
+		return
+	}
+
+	fieldsByID := make(map[string]*trello.CustomField, len(fields))
+	for _, field := range fields {
+		fieldsByID[field.ID] = field
+	}
+
+	items := make([]*trello.CustomFieldItem, 0, len(card.CustomFieldItems))
+	for _, item := range card.CustomFieldItems {
+		if _, exists := fieldsByID[item.IDCustomField]; exists {
+			items = append(items, item)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return fieldsByID[items[i].IDCustomField].Pos < fieldsByID[items[j].IDCustomField].Pos
+	})
+
+	var rows [][2]string
+	for _, item := range items {
+		field := fieldsByID[item.IDCustomField]
+		value := item.Value.Get()
+
+		switch c.options.CustomFields.target(field) {
+		case customFieldTargetIgnore:
+		case customFieldTargetLabel:
+			if label := c.customFieldLabel(field, item); label != nil {
+				task.Labels = append(task.Labels, label)
+			}
+		case customFieldTargetDueDate:
+			if date, ok := value.(time.Time); ok {
+				task.DueDate = date
+			}
+		case customFieldTargetStartDate:
+			if date, ok := value.(time.Time); ok {
+				task.StartDate = date
+			}
+		case customFieldTargetPriority:
+			if number, ok := customFieldNumber(value); ok {
+				task.Priority = int64(math.Max(0, math.Min(maxPriority, math.Round(number))))
+			}
+		default:
+			if text := customFieldText(field, item); text != "" {
+				rows = append(rows, [2]string{field.Name, text})
+			}
+		}
+	}
+
+	if len(rows) == 0 {
+		return
+	}
+
+	b := &markup.Builder{}
+	b.Raw("\n\n").Open("table").Open("tbody")
+	for _, row := range rows {
+		b.Open("tr").Element("th", row[0]).Element("td", row[1]).Close("tr")
+	}
+	b.Close("tbody").Close("table")
+	task.Description += b.String()
+}
+
+// customFieldLabel returns the label for a dropdown option or a checked
+// checkbox. Unchecked checkboxes and other field types yield no label.
+func (c *conversion) customFieldLabel(field *trello.CustomField, item *trello.CustomFieldItem) *models.Label {
+	switch field.Type {
+	case "checkbox":
+		if checked, _ := item.Value.Get().(bool); checked {
+			return c.convertLabel(field.Name, "", field.ID)
+		}
+	case "list":
+		option := customFieldOption(field, item.IDValue)
+		if option == nil {
+			return nil
+		}
+		return c.convertLabel(field.Name+": "+option.Value.Text, option.Color, option.ID)
+	default:
+		if text := customFieldText(field, item); text != "" {
+			return c.convertLabel(field.Name+": "+text, "", field.ID+text)
+		}
+	}
+
+	return nil
+}
+
+func customFieldOption(field *trello.CustomField, id string) *trello.CustomFieldOption {
+	for _, option := range field.Options {
+		if option.ID == id {
+			return option
+		}
+	}
+
+	return nil
+}
+
+func customFieldNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+
+	return 0, false
+}
+
+// customFieldText renders the value of a custom field item as plain text.
+func customFieldText(field *trello.CustomField, item *trello.CustomFieldItem) string {
+	if field.Type == "list" {
+		if option := customFieldOption(field, item.IDValue); option != nil {
+			return option.Value.Text
+		}
+		return ""
+	}
+
+	switch v := item.Value.Get().(type) {
+	case nil:
+		return ""
+	case bool:
+		if v {
+			return "Yes"
+		}
+		return "No"
+	case time.Time:
+		return v.Format(time.RFC1123)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,40 @@
+package trello2vikunja
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadCustomFieldConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{"valid", `{"Priority": "priority", "Deadline": "due_date", "Notes": "ignore"}`, ""},
+		{"empty", `{}`, ""},
+		{"typo", `{"Deadline": "due_date", "Priority": "priorty"}`, `unknown target "priorty" for custom field Priority`},
+		{"empty target", `{"Notes": ""}`, `unknown target "" for custom field Notes`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "custom_fields.json")
+			if err := os.WriteFile(filename, []byte(test.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := ReadCustomFieldConfig(filename)
+			if test.wantErr == "" {
+				if err != nil {
+					t.Errorf("ReadCustomFieldConfig: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("ReadCustomFieldConfig returned %v, want an error with %s", err, test.wantErr)
+			}
+		})
+	}
+}
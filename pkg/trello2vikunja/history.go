@@ -0,0 +1,148 @@
+package trello2vikunja
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/warrenwingaru/go-trello"
+	"wingaru.me/trello-migrate/pkg/markup"
+	"wingaru.me/trello-migrate/pkg/models"
+)
+
+// HistoryMode decides where the exported history of a card ends up. The
+// empty mode drops it.
+type HistoryMode string
+
+const (
+	// HistoryComment adds the card history as a single collapsible comment.
+	HistoryComment HistoryMode = "comment"
+	// HistoryDescription appends the card history to the task description.
+	HistoryDescription HistoryMode = "description"
+)
+
+const historyTimeFormat = "2006-01-02 15:04 MST"
+
+// convertCardHistory renders every non-comment action of a card as a
+// collapsible "Trello history" block, oldest first. It returns an empty
+// string when the card has no history to show.
+func convertCardHistory(actions trello.ActionCollection) string {
+	history := make(trello.ActionCollection, 0, len(actions))
+	for _, action := range actions {
+		if !action.DidCommentCard() {
+			history = append(history, action)
+		}
+	}
+
+	if len(history) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Date.Before(history[j].Date)
+	})
+
+	b := &markup.Builder{}
+	b.Open("details").Element("summary", "Trello history").Open("ul")
+	for _, action := range history {
+		b.Open("li")
+		b.Element("strong", action.Date.UTC().Format(historyTimeFormat))
+		b.Text(" " + actionActorName(action) + " " + describeAction(action))
+		b.Close("li")
+	}
+	b.Close("ul").Close("details")
+
+	return b.String()
+}
+
+// addCardHistory attaches the history of a card to task according to mode.
+func addCardHistory(task *models.TaskWithComments, card *trello.Card, mode HistoryMode) {
+	history := convertCardHistory(card.Actions)
+	if history == "" {
+		return
+	}
+
+	switch mode {
+	case HistoryComment:
+		created := latestActionDate(card.Actions)
+		task.Comments = append(task.Comments, &models.TaskComment{
+			Comment: history,
+			Created: created,
+			Updated: created,
+		})
+	case HistoryDescription:
+		task.Description += "\n\n" + history
+	}
+}
+
+func latestActionDate(actions trello.ActionCollection) (latest time.Time) {
+	for _, action := range actions {
+		if action.Date.After(latest) {
+			latest = action.Date
+		}
+	}
+
+	return
+}
+
+func actionActorName(action *trello.Action) string {
+	if action.MemberCreator == nil {
+		return "Someone"
+	}
+	if action.MemberCreator.FullName != "" {
+		return action.MemberCreator.FullName
+	}
+
+	return action.MemberCreator.Username
+}
+
+// describeAction returns a short, human-readable description of what an
+// action did to a card.
+func describeAction(action *trello.Action) string {
+	data := action.Data
+	if data == nil {
+		data = &trello.ActionData{}
+	}
+
+	switch {
+	case action.DidCreateCard():
+		if data.List != nil {
+			return fmt.Sprintf("created the card in %q", data.List.Name)
+		}
+		return "created the card"
+	case action.DidArchiveCard():
+		return "archived the card"
+	case action.DidUnarchiveCard():
+		return "sent the card back to the board"
+	case action.Type == "updateCard" && data.ListBefore != nil && data.ListAfter != nil:
+		return fmt.Sprintf("moved the card from %q to %q", data.ListBefore.Name, data.ListAfter.Name)
+	case action.Type == "addMemberToCard":
+		return "added " + memberName(action.Member) + " to the card"
+	case action.Type == "removeMemberFromCard":
+		return "removed " + memberName(action.Member) + " from the card"
+	case action.Type == "updateCheckItemStateOnCard" && data.CheckItem != nil:
+		if data.CheckItem.State == "complete" {
+			return fmt.Sprintf("completed %q", data.CheckItem.Name)
+		}
+		return fmt.Sprintf("marked %q incomplete", data.CheckItem.Name)
+	case action.Type == "addChecklistToCard" && data.Checklist != nil:
+		return fmt.Sprintf("added the checklist %q", data.Checklist.Name)
+	case action.Type == "addAttachmentToCard":
+		return "added an attachment"
+	case action.Type == "updateCard":
+		return "updated the card"
+	}
+
+	return "performed " + action.Type
+}
+
+func memberName(member *trello.Member) string {
+	if member == nil {
+		return "a member"
+	}
+	if member.FullName != "" {
+		return member.FullName
+	}
+
+	return member.Username
+}
@@ -0,0 +1,174 @@
+package trello2vikunja
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/warrenwingaru/go-trello"
+	"wingaru.me/trello-migrate/pkg/filesize"
+	"wingaru.me/trello-migrate/pkg/models"
+	"wingaru.me/trello-migrate/pkg/trellosource"
+)
+
+// OversizePolicy decides what happens to uploaded attachments larger than
+// Options.MaxAttachmentSize.
+type OversizePolicy string
+
+const (
+	// OversizeLink links the attachment on Trello instead of uploading it.
+	OversizeLink OversizePolicy = "link"
+	// OversizeZip uploads the attachment zipped, split into parts of at most
+	// MaxAttachmentSize when the zip is still too large.
+	OversizeZip OversizePolicy = "zip"
+	// OversizeFail makes Convert fail with an *OversizeError.
+	OversizeFail OversizePolicy = "fail"
+)
+
+// OversizedAttachment is an uploaded attachment larger than
+// Options.MaxAttachmentSize.
+type OversizedAttachment struct {
+	BoardID string
+	Board   string
+	CardID  string
+	Card    string
+	Name    string
+	URL     string
+	Size    int64
+	// Limit is the MaxAttachmentSize it exceeds.
+	Limit int64
+	// Parts is the number of files the attachment was zipped into, 0 when
+	// it was linked instead.
+	Parts int
+}
+
+// Reason tells why and how the attachment was changed, for reports.
+func (a OversizedAttachment) Reason() string {
+	reason := fmt.Sprintf("%s, more than the %s Vikunja accepts", filesize.Format(a.Size), filesize.Format(a.Limit))
+	switch {
+	case a.Parts == 0:
+		return reason + "; linked to Trello instead"
+	case a.Parts == 1:
+		return reason + "; uploaded zipped"
+	}
+
+	return fmt.Sprintf("%s; uploaded zipped in %d parts", reason, a.Parts)
+}
+
+// OversizeError is returned by Convert with OversizeFail when any
+// attachment is too large.
+type OversizeError struct {
+	Attachments []OversizedAttachment
+}
+
+func (e *OversizeError) Error() string {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "%d attachments are larger than Vikunja accepts:", len(e.Attachments))
+	for _, attachment := range e.Attachments {
+		fmt.Fprintf(&msg, "\n  %s on card %s of board %s: %s", attachment.Name, attachment.Card, attachment.Board, filesize.Format(attachment.Size))
+	}
+	msg.WriteString("\nlink or zip them with another oversize policy, or raise files.maxsize in the Vikunja config")
+
+	return msg.String()
+}
+
+// attachmentSize returns the size of the file of attachment read into buf,
+// or what the source knows about it when downloads are skipped.
+func (c *conversion) attachmentSize(attachment *trello.Attachment, buf *bytes.Buffer) (int64, bool) {
+	if !c.options.SkipDownloads {
+		return int64(buf.Len()), true
+	}
+	if sizer, ok := c.source.(trellosource.Sizer); ok {
+		return sizer.AttachmentSize(attachment)
+	}
+
+	return 0, false
+}
+
+// convertOversized records an attachment larger than MaxAttachmentSize and
+// returns the attachments to upload instead, none when it is linked.
+func (c *conversion) convertOversized(board *trellosource.Board, card *trello.Card, attachment *trello.Attachment, content []byte, size int64) ([]*models.TaskAttachment, error) {
+	oversized := OversizedAttachment{
+		BoardID: board.ID,
+		Board:   board.Name,
+		CardID:  card.ID,
+		Card:    card.Name,
+		Name:    attachment.Name,
+		URL:     attachment.URL,
+		Size:    size,
+		Limit:   c.options.MaxAttachmentSize,
+	}
+
+	var attachments []*models.TaskAttachment
+	switch {
+	case c.options.OversizePolicy != OversizeZip:
+	case c.options.SkipDownloads:
+		// Without the file, expect a part per MaxAttachmentSize.
+		limit := c.options.MaxAttachmentSize
+		for part := int64(0); part < (size+limit-1)/limit; part++ {
+			attachments = append(attachments, zipPart(attachment.Name+".zip", "application/zip", nil))
+		}
+	default:
+		var err error
+		attachments, err = zipAttachment(attachment.Name, content, c.options.MaxAttachmentSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+	oversized.Parts = len(attachments)
+	c.oversized = append(c.oversized, oversized)
+
+	return attachments, nil
+}
+
+// zipAttachment compresses content into name.zip. When that is still larger
+// than limit, it is cut into parts name.zip.001, name.zip.002 and so on,
+// which join into the zip again with cat or 7-Zip.
+func zipAttachment(name string, content []byte, limit int64) ([]*models.TaskAttachment, error) {
+	name = strings.ReplaceAll(name, "/", "_")
+	if name == "" {
+		name = "attachment"
+	}
+
+	buf := &bytes.Buffer{}
+	archive := zip.NewWriter(buf)
+	w, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(content); err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	zipped := buf.Bytes()
+	if int64(len(zipped)) <= limit {
+		return []*models.TaskAttachment{zipPart(name+".zip", "application/zip", zipped)}, nil
+	}
+
+	var parts []*models.TaskAttachment
+	for offset := int64(0); offset < int64(len(zipped)); offset += limit {
+		end := offset + limit
+		if end > int64(len(zipped)) {
+			end = int64(len(zipped))
+		}
+		partName := fmt.Sprintf("%s.zip.%03d", name, len(parts)+1)
+		parts = append(parts, zipPart(partName, "application/octet-stream", zipped[offset:end]))
+	}
+
+	return parts, nil
+}
+
+func zipPart(name string, mime string, content []byte) *models.TaskAttachment {
+	return &models.TaskAttachment{
+		File: &models.File{
+			Name:        name,
+			Mime:        mime,
+			Size:        uint64(len(content)),
+			FileContent: content,
+		},
+	}
+}
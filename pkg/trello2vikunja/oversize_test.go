@@ -0,0 +1,117 @@
+package trello2vikunja
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func TestZipAttachment(t *testing.T) {
+	// Random bytes barely compress, so the zip is larger than the limits.
+	content := make([]byte, 4099)
+	rand.New(rand.NewSource(1)).Read(content)
+
+	whole, err := zipAttachment("report.pdf", content, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := int64(len(whole[0].File.FileContent))
+	if size%4 != 0 {
+		t.Fatalf("the zip has %d bytes, the test needs a multiple of 4", size)
+	}
+
+	tests := []struct {
+		name  string
+		limit int64
+		parts []string
+		sizes []int64
+	}{
+		{"fits", size + 1, []string{"report.pdf.zip"}, []int64{size}},
+		{"exactly the limit", size, []string{"report.pdf.zip"}, []int64{size}},
+		{"one byte over", size - 1, []string{"report.pdf.zip.001", "report.pdf.zip.002"}, []int64{size - 1, 1}},
+		{
+			"split at exactly the limit", size / 4,
+			[]string{"report.pdf.zip.001", "report.pdf.zip.002", "report.pdf.zip.003", "report.pdf.zip.004"},
+			[]int64{size / 4, size / 4, size / 4, size / 4},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parts, err := zipAttachment("report.pdf", content, test.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(parts) != len(test.parts) {
+				t.Fatalf("got %d parts, want %d", len(parts), len(test.parts))
+			}
+
+			joined := &bytes.Buffer{}
+			for i, part := range parts {
+				if part.File.Name != test.parts[i] {
+					t.Errorf("part %d is named %s, want %s", i, part.File.Name, test.parts[i])
+				}
+				if int64(part.File.Size) != test.sizes[i] || int64(len(part.File.FileContent)) != test.sizes[i] {
+					t.Errorf("part %d has %d bytes, want %d", i, len(part.File.FileContent), test.sizes[i])
+				}
+				joined.Write(part.File.FileContent)
+			}
+
+			if got := unzipAttachment(t, joined.Bytes(), "report.pdf"); !bytes.Equal(got, content) {
+				t.Errorf("the joined parts unzip to %d bytes, want the %d bytes of the attachment", len(got), len(content))
+			}
+		})
+	}
+}
+
+func TestZipAttachmentName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"notes.txt", "notes.txt.zip"},
+		{"a/b.txt", "a_b.txt.zip"},
+		{"", "attachment.zip"},
+	}
+	for _, test := range tests {
+		parts, err := zipAttachment(test.name, []byte("content"), 1<<20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parts) != 1 {
+			t.Fatalf("zipAttachment(%q) returned %d parts, want one", test.name, len(parts))
+		}
+		if parts[0].File.Name != test.want {
+			t.Errorf("zipAttachment(%q) is named %s, want %s", test.name, parts[0].File.Name, test.want)
+		}
+	}
+}
+
+// unzipAttachment returns the content of the file name in the zip data.
+func unzipAttachment(t *testing.T, data []byte, name string) []byte {
+	t.Helper()
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("reading the zip: %v", err)
+	}
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		content, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return content
+	}
+
+	t.Fatalf("the zip has no file %s", name)
+	return nil
+}
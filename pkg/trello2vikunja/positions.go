@@ -0,0 +1,59 @@
+package trello2vikunja
+
+import (
+	"sort"
+
+	"github.com/warrenwingaru/go-trello"
+)
+
+// positionSpacing is the gap left between two consecutive task positions,
+// the same Vikunja uses when it assigns positions itself.
+const positionSpacing = 1 << 16
+
+// positionedViewTitles are the project views which get the Trello order of
+// the migrated tasks.
+var positionedViewTitles = []string{"Kanban", "List"}
+
+// sortedLists returns the lists of a board in their Trello order.
+func sortedLists(lists []*trello.List) []*trello.List {
+	sorted := make([]*trello.List, len(lists))
+	copy(sorted, lists)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Pos < sorted[j].Pos
+	})
+
+	return sorted
+}
+
+// sortedCheckItems returns the items of a checklist in their Trello order.
+func sortedCheckItems(items []trello.CheckItem) []trello.CheckItem {
+	sorted := make([]trello.CheckItem, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Pos < sorted[j].Pos
+	})
+
+	return sorted
+}
+
+// sortedCards returns the cards of a list in their Trello order.
+func sortedCards(cards []*trello.Card) []*trello.Card {
+	sorted := make([]*trello.Card, len(cards))
+	copy(sorted, cards)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Pos < sorted[j].Pos
+	})
+
+	return sorted
+}
+
+// taskPosition returns the position of the n-th task of a project.
+func taskPosition(n int) float64 {
+	return float64(n+1) * positionSpacing
+}
+
+// subtaskPosition returns the position of the n-th of total subtasks of the
+// task at parent, between it and the next task.
+func subtaskPosition(parent float64, n int, total int) float64 {
+	return parent + float64(n+1)*positionSpacing/float64(total+1)
+}
@@ -0,0 +1,206 @@
+package trellosource
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"github.com/warrenwingaru/go-trello"
+)
+
+// APISource reads boards from the Trello api.
+type APISource struct {
+	client *trello.Client
+	// History makes Comments return every action of a card instead of
+	// only its comments.
+	History bool
+	// Logger gets an entry for every checklist and comment mismatch. It
+	// discards them by default.
+	Logger logrus.FieldLogger
+}
+
+// discardLogger is the Logger of new sources.
+var discardLogger = &logrus.Logger{
+	Out:       io.Discard,
+	Formatter: new(logrus.TextFormatter),
+	Hooks:     make(logrus.LevelHooks),
+	Level:     logrus.PanicLevel,
+}
+
+// NewAPISource creates a source reading with client.
+func NewAPISource(client *trello.Client) *APISource {
+	return &APISource{client: client, Logger: discardLogger}
+}
+
+// Boards returns the boards of the user the client is authorized for.
+func (s *APISource) Boards() ([]*Board, error) {
+	trelloBoards, err := s.client.GetMyBoards(trello.Defaults())
+	if err != nil {
+		return nil, err
+	}
+
+	boards := make([]*Board, 0, len(trelloBoards))
+	for _, board := range trelloBoards {
+		customFields, err := board.GetCustomFields(trello.Defaults())
+		if err != nil {
+			return nil, err
+		}
+		boards = append(boards, &Board{Board: board, CustomFields: customFields})
+	}
+
+	return boards, nil
+}
+
+func (s *APISource) Lists(board *Board) ([]*trello.List, error) {
+	return board.GetFilteredLists("all", trello.Defaults())
+}
+
+// Cards returns the archived cards of board. The lists of the board are
+// only fetched when it has none yet.
+func (s *APISource) Cards(board *Board) ([]*trello.Card, error) {
+	lists := board.Lists
+	if lists == nil {
+		var err error
+		lists, err = s.Lists(board)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	listMap := make(map[string]*trello.List, len(lists))
+	for _, list := range lists {
+		listMap[list.ID] = list
+	}
+
+	allCards, err := s.AllCards(board.Board)
+	if err != nil {
+		return nil, err
+	}
+
+	var cards []*trello.Card
+	for _, card := range allCards {
+		list, exists := listMap[card.IDList]
+		if !exists || !Archived(list, card) {
+			continue
+		}
+
+		if err := s.LoadCard(board, card); err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+
+	return cards, nil
+}
+
+// AllCards returns all cards of board, open or archived, without their
+// checklists and attachments.
+func (s *APISource) AllCards(board *trello.Board) ([]*trello.Card, error) {
+	return board.GetFilteredCards("all", trello.Arguments{"fields": "all", "customFieldItems": "true"})
+}
+
+// LoadCard loads the attachments and checklists of card, keeping the due
+// dates and members of the checklist items in board.
+func (s *APISource) LoadCard(board *Board, card *trello.Card) (err error) {
+	allArg := trello.Arguments{"fields": "all"}
+
+	if card.Badges.Attachments > 0 {
+		card.Attachments, err = card.GetAttachments(allArg)
+		if err != nil {
+			return
+		}
+	}
+
+	for _, checkListID := range card.IDCheckLists {
+		checklist, err := s.client.GetChecklist(checkListID, allArg)
+		if err != nil {
+			return err
+		}
+
+		var items []checkItemJSON
+		err = s.client.Get("checklists/"+checkListID+"/checkItems", allArg, &items)
+		if err != nil {
+			return err
+		}
+		checklist.CheckItems = board.addCheckItems(items)
+
+		card.Checklists = append(card.Checklists, checklist)
+		s.Logger.WithFields(logrus.Fields{"card_id": card.ID, "checklist_id": checkListID}).Debug("Got checklist")
+	}
+
+	return
+}
+
+func (s *APISource) Comments(card *trello.Card) (actions []*trello.Action, err error) {
+	if s.History {
+		actions, err = getAllCardActions(card, "all")
+	} else if card.Badges.Comments > 0 {
+		actions, err = getAllCardActions(card, "commentCard")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	comments := 0
+	for _, action := range actions {
+		if action.DidCommentCard() {
+			comments++
+		}
+	}
+	if comments != card.Badges.Comments {
+		s.Logger.WithField("card_id", card.ID).Warnf("Card reports %d comments but %d were exported", card.Badges.Comments, comments)
+	}
+
+	return actions, nil
+}
+
+// Attachment downloads an uploaded attachment with the credentials of the
+// client.
+func (s *APISource) Attachment(attachment *trello.Attachment) (io.ReadCloser, error) {
+	attachments := Attachments{Key: s.client.Key, Token: s.client.Token, Client: s.client.Client, Logger: s.Logger}
+	return attachments.Open(attachment)
+}
+
+func (s *APISource) Members(board *Board) ([]*trello.Member, error) {
+	return board.GetMembers(trello.Defaults())
+}
+
+// actionPageLimit is the maximum number of actions Trello returns per request.
+const actionPageLimit = 1000
+
+// getAllCardActions pages through the actions of a card matching filter.
+// Trello returns actions newest first, so each following page is requested
+// with the id of the oldest action seen so far as the "before" cursor.
+func getAllCardActions(card *trello.Card, filter string) (actions trello.ActionCollection, err error) {
+	args := trello.Arguments{
+		"filter": filter,
+		"limit":  strconv.Itoa(actionPageLimit),
+	}
+
+	for {
+		page, err := card.GetActions(args)
+		if err != nil {
+			return nil, err
+		}
+
+		actions = append(actions, page...)
+		if len(page) < actionPageLimit {
+			break
+		}
+
+		args["before"] = oldestActionID(page)
+	}
+
+	return actions, nil
+}
+
+func oldestActionID(actions trello.ActionCollection) string {
+	oldest := actions[0].ID
+	for _, action := range actions {
+		if action.ID < oldest {
+			oldest = action.ID
+		}
+	}
+
+	return oldest
+}
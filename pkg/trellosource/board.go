@@ -0,0 +1,133 @@
+package trellosource
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/warrenwingaru/go-trello"
+)
+
+// Board is a Trello board as it is stored in trello.json. It carries the
+// board data which go-trello's Board type has no room for.
+type Board struct {
+	*trello.Board
+	// The custom field definitions of the board. Cards only reference them
+	// by id in their CustomFieldItems.
+	CustomFields []*trello.CustomField `json:"customFields,omitempty"`
+	// The due dates and members of checklist items by item id, for the
+	// items which have any.
+	CheckItems map[string]CheckItemDetails `json:"checkItems,omitempty"`
+}
+
+// CheckItemDetails are the fields of a checklist item which go-trello's
+// CheckItem has no room for.
+type CheckItemDetails struct {
+	Due      *time.Time `json:"due,omitempty"`
+	IDMember string     `json:"idMember,omitempty"`
+}
+
+// CheckItem returns the details of the checklist item id.
+func (b *Board) CheckItem(id string) CheckItemDetails {
+	return b.CheckItems[id]
+}
+
+// checkItemJSON is a checklist item as Trello sends it.
+type checkItemJSON struct {
+	trello.CheckItem
+	CheckItemDetails
+}
+
+// addCheckItems keeps the details of items and returns the items as
+// go-trello knows them.
+func (b *Board) addCheckItems(items []checkItemJSON) []trello.CheckItem {
+	checkItems := make([]trello.CheckItem, 0, len(items))
+	for _, item := range items {
+		if item.Due != nil || item.IDMember != "" {
+			if b.CheckItems == nil {
+				b.CheckItems = make(map[string]CheckItemDetails)
+			}
+			b.CheckItems[item.ID] = item.CheckItemDetails
+		}
+		checkItems = append(checkItems, item.CheckItem)
+	}
+
+	return checkItems
+}
+
+// ReadFile reads an export written by WriteFile.
+func ReadFile(filename string) ([]*Board, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var boards []*Board
+	if err := json.NewDecoder(file).Decode(&boards); err != nil {
+		return nil, err
+	}
+
+	return boards, nil
+}
+
+// WriteFile writes boards to filename as json.
+func WriteFile(filename string, boards []*Board) error {
+	data, err := marshalBoards(boards)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, data, 0644)
+}
+
+// boardJSON, listJSON and cardJSON shadow the go-trello fields down to the
+// custom field items. go-trello fails to marshal items without a value, like
+// those of dropdowns, which only have an idValue.
+type boardJSON struct {
+	*Board
+	Lists []*listJSON `json:"lists"`
+}
+
+type listJSON struct {
+	*trello.List
+	Cards []*cardJSON `json:"cards,omitempty"`
+}
+
+type cardJSON struct {
+	*trello.Card
+	CustomFieldItems []*customFieldItemJSON `json:"customFieldItems,omitempty"`
+}
+
+type customFieldItemJSON struct {
+	*trello.CustomFieldItem
+	// Value is nil for items without one, so it is left out.
+	Value *trello.CustomFieldValue `json:"value,omitempty"`
+}
+
+func marshalBoards(boards []*Board) ([]byte, error) {
+	out := make([]*boardJSON, 0, len(boards))
+	for _, board := range boards {
+		b := &boardJSON{Board: board}
+		if board.Board != nil {
+			for _, list := range board.Lists {
+				l := &listJSON{List: list}
+				for _, card := range list.Cards {
+					c := &cardJSON{Card: card}
+					for _, item := range card.CustomFieldItems {
+						i := &customFieldItemJSON{CustomFieldItem: item}
+						if item.Value.Get() != nil {
+							i.Value = &item.Value
+						}
+						c.CustomFieldItems = append(c.CustomFieldItems, i)
+					}
+					l.Cards = append(l.Cards, c)
+				}
+				b.Lists = append(b.Lists, l)
+			}
+		}
+		out = append(out, b)
+	}
+
+	return json.Marshal(out)
+}
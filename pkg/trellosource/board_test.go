@@ -0,0 +1,131 @@
+package trellosource
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/warrenwingaru/go-trello"
+)
+
+func TestWriteFileRoundTripsCustomFieldItems(t *testing.T) {
+	var items []*trello.CustomFieldItem
+	err := json.Unmarshal([]byte(`[
+		{"id":"a","idValue":"opt","idCustomField":"dropdown"},
+		{"id":"b","value":{"text":"hello"},"idCustomField":"text"},
+		{"id":"c","value":{"checked":"true"},"idCustomField":"checkbox"}
+	]`), &items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	boards := []*Board{{
+		Board: &trello.Board{
+			ID:   "board",
+			Name: "Board",
+			Lists: []*trello.List{{
+				ID:    "list",
+				Cards: []*trello.Card{{ID: "card", CustomFieldItems: items}},
+			}},
+		},
+		CustomFields: []*trello.CustomField{{ID: "dropdown", Name: "Priority", Type: "list"}},
+	}}
+
+	filename := filepath.Join(t.TempDir(), "trello.json")
+	if err := WriteFile(filename, boards); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	read, err := ReadFile(filename)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if len(read) != 1 || len(read[0].Lists) != 1 || len(read[0].Lists[0].Cards) != 1 {
+		t.Fatalf("read %d boards, want the board with its list and card", len(read))
+	}
+	if len(read[0].CustomFields) != 1 || read[0].CustomFields[0].ID != "dropdown" {
+		t.Errorf("custom fields = %+v, want the dropdown", read[0].CustomFields)
+	}
+
+	got := read[0].Lists[0].Cards[0].CustomFieldItems
+	if len(got) != len(items) {
+		t.Fatalf("read %d custom field items, want %d", len(got), len(items))
+	}
+
+	tests := []struct {
+		id            string
+		idCustomField string
+		idValue       string
+		value         interface{}
+	}{
+		{"a", "dropdown", "opt", nil},
+		{"b", "text", "", "hello"},
+		{"c", "checkbox", "", true},
+	}
+	for i, test := range tests {
+		item := got[i]
+		if item.ID != test.id || item.IDCustomField != test.idCustomField || item.IDValue != test.idValue {
+			t.Errorf("item %d = %+v, want id %s, field %s, idValue %q", i, item, test.id, test.idCustomField, test.idValue)
+		}
+		if item.Value.Get() != test.value {
+			t.Errorf("item %s has value %v, want %v", test.id, item.Value.Get(), test.value)
+		}
+	}
+}
+
+func TestCheckItemDetails(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "board.json")
+	err := os.WriteFile(filename, []byte(`{
+		"id": "board",
+		"name": "Board",
+		"lists": [{"id": "list", "closed": true}],
+		"cards": [{"id": "card", "idList": "list"}],
+		"checklists": [{
+			"id": "checklist",
+			"idCard": "card",
+			"checkItems": [
+				{"id": "a", "name": "due", "due": "2024-05-01T12:00:00.000Z"},
+				{"id": "b", "name": "assigned", "idMember": "member"},
+				{"id": "c", "name": "plain"}
+			]
+		}]
+	}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	board, err := ReadUIExport(filename)
+	if err != nil {
+		t.Fatalf("ReadUIExport: %v", err)
+	}
+	if items := board.Lists[0].Cards[0].Checklists[0].CheckItems; len(items) != 3 || items[0].Name != "due" {
+		t.Fatalf("the checklist has the items %+v", items)
+	}
+
+	exported := filepath.Join(t.TempDir(), "trello.json")
+	if err := WriteFile(exported, []*Board{board}); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	read, err := ReadFile(exported)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	want := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, b := range []*Board{board, read[0]} {
+		if len(b.CheckItems) != 2 {
+			t.Errorf("the board has details of %d items, want 2: %+v", len(b.CheckItems), b.CheckItems)
+		}
+		if due := b.CheckItem("a").Due; due == nil || !due.Equal(want) {
+			t.Errorf("item a is due %v, want %v", due, want)
+		}
+		if member := b.CheckItem("b").IDMember; member != "member" {
+			t.Errorf("item b is assigned to %q, want member", member)
+		}
+		if details := b.CheckItem("c"); details.Due != nil || details.IDMember != "" {
+			t.Errorf("item c has details %+v, want none", details)
+		}
+	}
+}
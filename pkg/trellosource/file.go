@@ -0,0 +1,80 @@
+package trellosource
+
+import (
+	"io"
+
+	"github.com/warrenwingaru/go-trello"
+)
+
+// FileSource reads boards which were exported before, either by the
+// exporter or from the Trello UI. The files of uploaded attachments are
+// read from its Attachments.
+type FileSource struct {
+	Attachments
+
+	boards []*Board
+}
+
+// OpenFile reads the trello.json written by the exporter.
+func OpenFile(filename string, attachments Attachments) (*FileSource, error) {
+	boards, err := ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSource{Attachments: attachments, boards: boards}, nil
+}
+
+// OpenUIExports reads boards exported from the Trello UI.
+func OpenUIExports(filenames []string, attachments Attachments) (*FileSource, error) {
+	boards, err := ReadUIExports(filenames)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSource{Attachments: attachments, boards: boards}, nil
+}
+
+func (s *FileSource) Boards() ([]*Board, error) {
+	return s.boards, nil
+}
+
+func (s *FileSource) Lists(board *Board) ([]*trello.List, error) {
+	return board.Lists, nil
+}
+
+func (s *FileSource) Cards(board *Board) ([]*trello.Card, error) {
+	var cards []*trello.Card
+	for _, list := range board.Lists {
+		cards = append(cards, list.Cards...)
+	}
+
+	return cards, nil
+}
+
+func (s *FileSource) Comments(card *trello.Card) ([]*trello.Action, error) {
+	return card.Actions, nil
+}
+
+func (s *FileSource) Attachment(attachment *trello.Attachment) (io.ReadCloser, error) {
+	return s.Attachments.Open(attachment)
+}
+
+// Members returns the members of the cards of board, as the exports only
+// keep those.
+func (s *FileSource) Members(board *Board) ([]*trello.Member, error) {
+	var members []*trello.Member
+	seen := map[string]bool{}
+	for _, list := range board.Lists {
+		for _, card := range list.Cards {
+			for _, member := range card.Members {
+				if !seen[member.ID] {
+					seen[member.ID] = true
+					members = append(members, member)
+				}
+			}
+		}
+	}
+
+	return members, nil
+}
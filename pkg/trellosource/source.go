@@ -0,0 +1,203 @@
+// Package trellosource reads Trello boards for the migration, no matter if
+// they come from the api, from the exporter's trello.json or from the json
+// export of the Trello UI.
+package trellosource
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/warrenwingaru/go-trello"
+)
+
+// Source is where Trello boards are read from.
+type Source interface {
+	// Boards returns the boards with their custom fields but without lists.
+	Boards() ([]*Board, error)
+	// Lists returns all lists of board, including the archived ones.
+	Lists(board *Board) ([]*trello.List, error)
+	// Cards returns the archived cards of board and the cards of its
+	// archived lists, with their checklists and attachments.
+	Cards(board *Board) ([]*trello.Card, error)
+	// Comments returns the actions of card, newest first. Those are its
+	// comments, and its history when the source has it.
+	Comments(card *trello.Card) ([]*trello.Action, error)
+	// Attachment opens the file of an uploaded attachment.
+	Attachment(attachment *trello.Attachment) (io.ReadCloser, error)
+	// Members returns the members of board.
+	Members(board *Board) ([]*trello.Member, error)
+}
+
+// Sizer is implemented by sources which know the size of the file of an
+// uploaded attachment without reading it.
+type Sizer interface {
+	// AttachmentSize returns the size of the file of attachment, and false
+	// when it is unknown.
+	AttachmentSize(attachment *trello.Attachment) (int64, bool)
+}
+
+// ErrAttachmentUnavailable is returned by Attachment when the file of an
+// attachment can't be read without api credentials.
+var ErrAttachmentUnavailable = errors.New("attachment is not available without Trello credentials")
+
+// Archived reports whether card is migrated: Only archived cards and the
+// cards of archived lists are.
+func Archived(list *trello.List, card *trello.Card) bool {
+	return list.Closed || card.Closed
+}
+
+// Load reads all boards of source with their lists, the archived cards in
+// them and the comments and members of those cards, nested like in
+// trello.json.
+func Load(source Source) ([]*Board, error) {
+	boards, err := source.Boards()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, board := range boards {
+		lists, err := source.Lists(board)
+		if err != nil {
+			return nil, err
+		}
+		// Sources which need the lists to find the cards reuse them.
+		board.Lists = lists
+		cards, err := source.Cards(board)
+		if err != nil {
+			return nil, err
+		}
+		members, err := source.Members(board)
+		if err != nil {
+			return nil, err
+		}
+
+		memberMap := make(map[string]*trello.Member, len(members))
+		for _, member := range members {
+			memberMap[member.ID] = member
+		}
+
+		listMap := make(map[string]*trello.List, len(lists))
+		for _, list := range lists {
+			list.Cards = nil
+			listMap[list.ID] = list
+		}
+
+		for _, card := range cards {
+			list, exists := listMap[card.IDList]
+			if !exists {
+				continue
+			}
+
+			card.Actions, err = source.Comments(card)
+			if err != nil {
+				return nil, err
+			}
+
+			card.Members = nil
+			for _, memberID := range card.IDMembers {
+				if member, exists := memberMap[memberID]; exists {
+					card.Members = append(card.Members, member)
+				}
+			}
+
+			list.Cards = append(list.Cards, card)
+		}
+
+		board.Lists = lists
+	}
+
+	return boards, nil
+}
+
+// Attachments finds the files of uploaded attachments: in Dir, where the
+// exporter stores them by attachment id, or else on Trello when Key and
+// Token are set.
+type Attachments struct {
+	Dir   string
+	Key   string
+	Token string
+	// Client downloads the attachments, http.DefaultClient when nil.
+	Client *http.Client
+	// Logger gets an entry for every download. It discards them when nil.
+	Logger logrus.FieldLogger
+}
+
+// Open opens the file of attachment.
+func (a *Attachments) Open(attachment *trello.Attachment) (io.ReadCloser, error) {
+	if a.Dir != "" {
+		file, err := os.Open(a.Path(attachment))
+		if err == nil {
+			return file, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	if a.Key == "" || a.Token == "" {
+		return nil, fmt.Errorf("%s: %w", attachment.Name, ErrAttachmentUnavailable)
+	}
+
+	return a.download(attachment)
+}
+
+// Path returns where the file of attachment is stored in Dir.
+func (a *Attachments) Path(attachment *trello.Attachment) string {
+	return filepath.Join(a.Dir, attachment.ID)
+}
+
+// AttachmentSize returns the size of the file of attachment in Dir.
+func (a *Attachments) AttachmentSize(attachment *trello.Attachment) (int64, bool) {
+	if a.Dir == "" {
+		return 0, false
+	}
+
+	info, err := os.Stat(a.Path(attachment))
+	if err != nil {
+		return 0, false
+	}
+
+	return info.Size(), true
+}
+
+// download fetches an uploaded attachment, which Trello only serves with
+// the OAuth header of the api credentials.
+func (a *Attachments) download(attachment *trello.Attachment) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, attachment.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", `OAuth oauth_consumer_key="`+a.Key+`", oauth_token="`+a.Token+`"`)
+
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	started := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.Logger != nil {
+		a.Logger.WithFields(logrus.Fields{
+			"attachment_id": attachment.ID,
+			"http_status":   resp.StatusCode,
+			"duration":      time.Since(started).Round(time.Millisecond).String(),
+		}).Debug("Downloaded attachment")
+	}
+
+	if resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("downloading attachment %s: %s", attachment.Name, resp.Status)
+	}
+
+	return resp.Body, nil
+}
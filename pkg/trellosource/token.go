@@ -0,0 +1,62 @@
+package trellosource
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/warrenwingaru/go-trello"
+)
+
+// TokenScope is the scope the exporter needs: reading all boards and their
+// attachments.
+const TokenScope = "read"
+
+// TokenExpirations are the lifetimes Trello offers for tokens.
+var TokenExpirations = []string{"1hour", "1day", "30days", "never"}
+
+// AuthorizeURL returns the page where a user authorizes the api key for
+// TokenScope. Trello then redirects to returnURL with the token in the
+// fragment, like #token=..., which only the browser sees. The origin of
+// returnURL must be allowed for the key.
+func AuthorizeURL(key string, name string, expiration string, returnURL string) string {
+	query := url.Values{
+		"key":             {key},
+		"name":            {name},
+		"scope":           {TokenScope},
+		"expiration":      {expiration},
+		"response_type":   {"token"},
+		"callback_method": {"fragment"},
+		"return_url":      {returnURL},
+	}
+
+	return "https://trello.com/1/authorize?" + query.Encode()
+}
+
+// CheckToken makes sure the token of client is valid and can read all boards
+// of the user, and returns it with its expiry date.
+func CheckToken(client *trello.Client) (*trello.Token, error) {
+	if client.Key == "" || client.Token == "" {
+		return nil, fmt.Errorf("the Trello api key and token are not set, run trello-vikunja auth trello to get a token")
+	}
+
+	token, err := client.GetToken(client.Token, trello.Defaults())
+	if trello.IsPermissionDenied(err) || trello.IsNotFound(err) {
+		return nil, fmt.Errorf("the Trello token is invalid or has expired, run trello-vikunja auth trello to get a new one: %w", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if token.DateExpires != nil && token.DateExpires.Before(time.Now()) {
+		return nil, fmt.Errorf("the Trello token expired at %s, run trello-vikunja auth trello to get a new one", token.DateExpires.Format(time.RFC1123))
+	}
+
+	for _, permission := range token.Permissions {
+		if permission.ModelType == "Board" && permission.IDModel == "*" && permission.Read {
+			return token, nil
+		}
+	}
+
+	return nil, fmt.Errorf("the Trello token can't read all boards, run trello-vikunja auth trello to get one with the %s scope", TokenScope)
+}
@@ -0,0 +1,117 @@
+package trellosource
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/warrenwingaru/go-trello"
+)
+
+// uiExport is a board as exported through "Print, export and share" in the
+// Trello UI. Unlike the api, it has all cards, checklists and actions of the
+// board at the top level instead of nested in their lists and cards.
+type uiExport struct {
+	trello.Board
+	Cards        []*trello.Card        `json:"cards"`
+	Checklists   []*uiChecklist        `json:"checklists"`
+	Members      []*trello.Member      `json:"members"`
+	CustomFields []*trello.CustomField `json:"customFields"`
+}
+
+// uiChecklist is a checklist of a uiExport, whose items have their due
+// dates and members.
+type uiChecklist struct {
+	*trello.Checklist
+	CheckItems []checkItemJSON `json:"checkItems"`
+}
+
+// ReadUIExport reads a board exported from the Trello UI. Like the exporter,
+// it only keeps the archived cards and the cards of archived lists, with
+// their checklists, members and actions.
+func ReadUIExport(filename string) (*Board, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var export uiExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("reading Trello board export %s: %w", filename, err)
+	}
+	if export.ID == "" || export.Lists == nil {
+		return nil, fmt.Errorf("%s is not a Trello board export", filename)
+	}
+
+	board := &export.Board
+	result := &Board{Board: board, CustomFields: export.CustomFields}
+	actions := board.Actions
+	board.Actions = nil
+
+	lists := make(map[string]*trello.List, len(board.Lists))
+	for _, list := range board.Lists {
+		list.Cards = nil
+		lists[list.ID] = list
+	}
+
+	members := make(map[string]*trello.Member, len(export.Members))
+	for _, member := range export.Members {
+		members[member.ID] = member
+	}
+
+	cards := make(map[string]*trello.Card, len(export.Cards))
+	for _, card := range export.Cards {
+		list, exists := lists[card.IDList]
+		if !exists || !Archived(list, card) {
+			continue
+		}
+
+		for _, memberID := range card.IDMembers {
+			if member, exists := members[memberID]; exists {
+				card.Members = append(card.Members, member)
+			}
+		}
+
+		list.Cards = append(list.Cards, card)
+		cards[card.ID] = card
+	}
+
+	sort.SliceStable(export.Checklists, func(i, j int) bool {
+		return export.Checklists[i].Pos < export.Checklists[j].Pos
+	})
+	for _, checklist := range export.Checklists {
+		if card, exists := cards[checklist.IDCard]; exists {
+			checklist.Checklist.CheckItems = result.addCheckItems(checklist.CheckItems)
+			card.Checklists = append(card.Checklists, checklist.Checklist)
+		}
+	}
+
+	for _, action := range actions {
+		if action.Data == nil || action.Data.Card == nil {
+			continue
+		}
+		if card, exists := cards[action.Data.Card.ID]; exists {
+			if action.MemberCreator == nil {
+				action.MemberCreator = members[action.IDMemberCreator]
+			}
+			card.Actions = append(card.Actions, action)
+		}
+	}
+
+	return result, nil
+}
+
+// ReadUIExports reads several boards exported from the Trello UI.
+func ReadUIExports(filenames []string) ([]*Board, error) {
+	boards := make([]*Board, 0, len(filenames))
+	for _, filename := range filenames {
+		board, err := ReadUIExport(filename)
+		if err != nil {
+			return nil, err
+		}
+		boards = append(boards, board)
+	}
+
+	return boards, nil
+}
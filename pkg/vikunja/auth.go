@@ -0,0 +1,215 @@
+package vikunja
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"wingaru.me/trello-migrate/pkg/models"
+)
+
+// renewBefore is how long before it expires the token of a login is renewed.
+const renewBefore = 10 * time.Minute
+
+// session is the token of a login. The copies of a client share it, so all
+// of them use the renewed token.
+type session struct {
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// Login logs in as username and makes the client use the token it gets,
+// renewing it shortly before it expires. totp is the current passcode for
+// users with two factor authentication, empty otherwise.
+func (c *Client) Login(username string, password string, totp string) error {
+	data, err := json.Marshal(map[string]string{
+		"username":      username,
+		"password":      password,
+		"totp_passcode": totp,
+	})
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Token string `json:"token"`
+	}
+	err = c.post("login", bytes.NewBuffer(data), &response)
+	if err != nil {
+		return errors.Wrapf(err, "logging in to Vikunja as %s", username)
+	}
+	if response.Token == "" {
+		return fmt.Errorf("logging in to Vikunja as %s returned no token", username)
+	}
+
+	c.Key = response.Token
+	c.session = &session{token: response.Token, expires: tokenExpiry(response.Token)}
+	return nil
+}
+
+// UseToken makes the client authenticate with token, like an api token,
+// instead of its login.
+func (c *Client) UseToken(token string) {
+	c.Key = token
+	c.session = nil
+}
+
+// authorize sets the token of the client on req, renewing the login when it
+// is about to expire.
+func (c *Client) authorize(req *http.Request) error {
+	token := c.Key
+	if c.session != nil {
+		var err error
+		token, err = c.session.current(c)
+		if err != nil {
+			return err
+		}
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
+
+// current returns the token of the session, renewed with c when it expires
+// within renewBefore.
+func (s *session) current(c *Client) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.expires.IsZero() || time.Until(s.expires) > renewBefore {
+		return s.token, nil
+	}
+	if time.Now().After(s.expires) {
+		return "", fmt.Errorf("the Vikunja login expired at %s, log in again", s.expires.Format(time.RFC1123))
+	}
+
+	c.Throttle()
+	url := fmt.Sprintf("%s/user/token", c.BaseURL)
+	req, err := http.NewRequest("POST", url, nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "Invalid POST request %s", url)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+
+	var response struct {
+		Token string `json:"token"`
+	}
+	err = c.do(req, url, &response)
+	if err != nil {
+		return "", errors.Wrap(err, "renewing the Vikunja login")
+	}
+	if response.Token == "" {
+		return "", fmt.Errorf("renewing the Vikunja login returned no token")
+	}
+
+	s.token = response.Token
+	s.expires = tokenExpiry(response.Token)
+	c.Logger.WithField("expires", s.expires.Format(time.RFC3339)).Debug("Renewed the Vikunja login")
+	return s.token, nil
+}
+
+// tokenExpiry returns when the JWT token expires, or the zero time when it
+// can't be read. The signature isn't checked, the server does that.
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(claims.Exp, 0)
+}
+
+// migrationRouteGroups are the route groups the migration uses. Groups of
+// their sub resources, like tasks_attachments, are included.
+var migrationRouteGroups = []string{"projects", "tasks", "labels"}
+
+// GetTokenRoutes returns the routes api tokens can get permissions for, by
+// route group.
+func (c *Client) GetTokenRoutes() (routes map[string]map[string]json.RawMessage, err error) {
+	err = c.get("routes", &routes)
+	if err != nil {
+		return nil, err
+	}
+
+	return routes, nil
+}
+
+// MigrationPermissions returns all permissions of routes on projects, tasks
+// with their comments, attachments and relations, and labels.
+func MigrationPermissions(routes map[string]map[string]json.RawMessage) map[string][]string {
+	permissions := make(map[string][]string)
+	for group, groupRoutes := range routes {
+		for _, prefix := range migrationRouteGroups {
+			if group != prefix && !strings.HasPrefix(group, prefix+"_") {
+				continue
+			}
+
+			for route := range groupRoutes {
+				permissions[group] = append(permissions[group], route)
+			}
+			sort.Strings(permissions[group])
+		}
+	}
+
+	return permissions
+}
+
+// CreateAPIToken creates token. Its Token is only set in the response.
+func (c *Client) CreateAPIToken(token *models.APIToken) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	return c.put("tokens", bytes.NewBuffer(data), &token)
+}
+
+func (c *Client) DeleteAPIToken(tokenID int64) error {
+	path := fmt.Sprintf("tokens/%d", tokenID)
+	return c.del(path)
+}
+
+// CreateMigrationToken creates an api token with the MigrationPermissions
+// of the instance, which expires after lifetime.
+func (c *Client) CreateMigrationToken(title string, lifetime time.Duration) (*models.APIToken, error) {
+	routes, err := c.GetTokenRoutes()
+	if err != nil {
+		return nil, err
+	}
+
+	token := &models.APIToken{
+		Title:       title,
+		Permissions: MigrationPermissions(routes),
+		ExpiresAt:   time.Now().Add(lifetime),
+	}
+	err = c.CreateAPIToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if token.Token == "" {
+		return nil, fmt.Errorf("creating the api token %s returned no token", title)
+	}
+
+	return token, nil
+}
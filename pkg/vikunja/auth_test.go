@@ -0,0 +1,32 @@
+package vikunja
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestTokenExpiry(t *testing.T) {
+	jwt := func(payload string) string {
+		return "eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".signature"
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  time.Time
+	}{
+		{"exp claim", jwt(`{"exp":1760000000,"id":1}`), time.Unix(1760000000, 0)},
+		{"no exp claim", jwt(`{"id":1}`), time.Time{}},
+		{"zero exp claim", jwt(`{"exp":0}`), time.Time{}},
+		{"payload not json", jwt(`not json`), time.Time{}},
+		{"payload not base64", "header.!!!.signature", time.Time{}},
+		{"api token", "tk_0123456789abcdef", time.Time{}},
+		{"empty", "", time.Time{}},
+	}
+	for _, test := range tests {
+		if got := tokenExpiry(test.token); !got.Equal(test.want) {
+			t.Errorf("%s: tokenExpiry(%q) = %v, want %v", test.name, test.token, got, test.want)
+		}
+	}
+}
@@ -6,26 +6,37 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/time/rate"
 	"io"
 	"io/ioutil"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"time"
-	"wingaru.me/trello-migrate/internal/models"
+	"wingaru.me/trello-migrate/pkg/models"
 )
 
 type Client struct {
-	Client   *http.Client
-	Logger   logger
-	BaseURL  string
-	Key      string
+	Client *http.Client
+	// Logger gets an entry for every request. It discards them by default.
+	Logger  logrus.FieldLogger
+	BaseURL string
+	Key     string
+	// session is set by Login, to renew the token before it expires.
+	session *session
+	// Info is what Detect read from the instance, nil before.
+	Info     *Info
 	throttle *rate.Limiter
 	ctx      context.Context
 }
 
-type logger interface {
-	Debugf(string, ...interface{})
+// discardLogger is the Logger of new clients.
+var discardLogger = &logrus.Logger{
+	Out:       io.Discard,
+	Formatter: new(logrus.TextFormatter),
+	Hooks:     make(logrus.LevelHooks),
+	Level:     logrus.PanicLevel,
 }
 
 func NewClient(key string, instanceUrl string) *Client {
@@ -33,6 +44,7 @@ func NewClient(key string, instanceUrl string) *Client {
 	return &Client{
 		Key:      key,
 		Client:   http.DefaultClient,
+		Logger:   discardLogger,
 		BaseURL:  instanceUrl,
 		throttle: rate.NewLimiter(limit, 1),
 		ctx:      context.Background(),
@@ -45,15 +57,14 @@ func (c *Client) WithContext(ctx context.Context) *Client {
 	return &newC
 }
 
-func (c *Client) Throttle() {
-	c.throttle.Wait(c.ctx)
-
+// RateLimit returns the number of requests the client makes per second at
+// most.
+func (c *Client) RateLimit() rate.Limit {
+	return c.throttle.Limit()
 }
 
-func (c *Client) log(format string, args ...interface{}) {
-	if c.Logger != nil {
-		c.Logger.Debugf(format, args...)
-	}
+func (c *Client) Throttle() {
+	c.throttle.Wait(c.ctx)
 
 }
 
@@ -66,8 +77,39 @@ func (h httpClientError) Error() string {
 	return h.msg
 }
 
-func (c *Client) do(req *http.Request, url string, target interface{}) error {
+// IsNotFound reports whether err is the response to a request for something
+// which does not exist.
+func IsNotFound(err error) bool {
+	clientErr, ok := err.(*httpClientError)
+	return ok && clientErr.code == http.StatusNotFound
+}
+
+// send makes req and logs it with its status and duration.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	started := time.Now()
 	resp, err := c.Client.Do(req)
+	entry := c.Logger.WithFields(logrus.Fields{
+		"method":   req.Method,
+		"path":     req.URL.Path,
+		"duration": time.Since(started).Round(time.Millisecond).String(),
+	})
+	if err != nil {
+		entry.WithError(err).Warn("Vikunja request failed")
+		return nil, err
+	}
+
+	entry = entry.WithField("http_status", resp.StatusCode)
+	if resp.StatusCode > 299 && resp.StatusCode != http.StatusNotFound {
+		entry.Warn("Vikunja request failed")
+	} else {
+		entry.Debug("Vikunja request")
+	}
+
+	return resp, nil
+}
+
+func (c *Client) do(req *http.Request, url string, target interface{}) error {
+	resp, err := c.send(req)
 	if err != nil {
 		return errors.Wrapf(err, "http request failed on %s", url)
 	}
@@ -93,10 +135,60 @@ func (c *Client) do(req *http.Request, url string, target interface{}) error {
 	return nil
 }
 
+func (c *Client) get(path string, target interface{}) error {
+	c.Throttle()
+
+	url := fmt.Sprintf("%s/%s", c.BaseURL, path)
+
+	req, err := http.NewRequest("GET", url, nil)
+
+	if err != nil {
+		return errors.Wrapf(err, "Invalid GET request %s", url)
+	}
+	if err := c.authorize(req); err != nil {
+		return err
+	}
+	return c.do(req, url, target)
+}
+
+// getRaw returns the body of a GET request, for endpoints returning files.
+func (c *Client) getRaw(path string) ([]byte, error) {
+	c.Throttle()
+
+	url := fmt.Sprintf("%s/%s", c.BaseURL, path)
+
+	req, err := http.NewRequest("GET", url, nil)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid GET request %s", url)
+	}
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "http request failed on %s", url)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "http read error on response for %s", url)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &httpClientError{
+			msg:  fmt.Sprintf("HTTP request failure on %s:\n%d: %s", url, resp.StatusCode, string(body)),
+			code: resp.StatusCode,
+		}
+	}
+
+	return body, nil
+}
+
 func (c *Client) put(path string, body io.Reader, target interface{}) error {
 	c.Throttle()
 
-	c.log("[vikunja] PUT %s", path)
 	url := fmt.Sprintf("%s/%s", c.BaseURL, path)
 
 	req, err := http.NewRequest("PUT", url, body)
@@ -104,7 +196,9 @@ func (c *Client) put(path string, body io.Reader, target interface{}) error {
 	if err != nil {
 		return errors.Wrapf(err, "Invalid PUT request %s", url)
 	}
-	req.Header.Set("Authorization", "Bearer "+c.Key)
+	if err := c.authorize(req); err != nil {
+		return err
+	}
 	req.Header.Set("Content-Type", "application/json")
 	return c.do(req, url, target)
 }
@@ -112,7 +206,6 @@ func (c *Client) put(path string, body io.Reader, target interface{}) error {
 func (c *Client) putMultipart(path string, body io.Reader, target interface{}, writer *multipart.Writer) error {
 	c.Throttle()
 
-	c.log("[vikunja] PUT %s", path)
 	url := fmt.Sprintf("%s/%s", c.BaseURL, path)
 
 	req, err := http.NewRequest("PUT", url, body)
@@ -120,7 +213,9 @@ func (c *Client) putMultipart(path string, body io.Reader, target interface{}, w
 	if err != nil {
 		return errors.Wrapf(err, "Invalid PUT request %s", url)
 	}
-	req.Header.Set("Authorization", "Bearer "+c.Key)
+	if err := c.authorize(req); err != nil {
+		return err
+	}
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	return c.do(req, url, target)
 }
@@ -128,7 +223,6 @@ func (c *Client) putMultipart(path string, body io.Reader, target interface{}, w
 func (c *Client) post(path string, body io.Reader, target interface{}) error {
 	c.Throttle()
 
-	c.log("[vikunja] POST %s", path)
 	url := fmt.Sprintf("%s/%s", c.BaseURL, path)
 
 	req, err := http.NewRequest("POST", url, body)
@@ -137,13 +231,91 @@ func (c *Client) post(path string, body io.Reader, target interface{}) error {
 		return errors.Wrapf(err, "Invalid POST request %s", url)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.Key)
+	if err := c.authorize(req); err != nil {
+		return err
+	}
 	req.Header.Set("Content-Type", "application/json")
 	return c.do(req, url, target)
 }
 
+func (c *Client) del(path string) error {
+	c.Throttle()
+
+	url := fmt.Sprintf("%s/%s", c.BaseURL, path)
+
+	req, err := http.NewRequest("DELETE", url, nil)
+
+	if err != nil {
+		return errors.Wrapf(err, "Invalid DELETE request %s", url)
+	}
+	if err := c.authorize(req); err != nil {
+		return err
+	}
+	return c.do(req, url, &struct{}{})
+}
+
+func (c *Client) GetProject(projectID int64) (project *models.Project, err error) {
+	path := fmt.Sprintf("projects/%d", projectID)
+	err = c.get(path, &project)
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func (c *Client) UpdateProject(project *models.Project) error {
+	path := fmt.Sprintf("projects/%d", project.ID)
+	data, err := json.Marshal(project)
+	if err != nil {
+		return err
+	}
+	err = c.post(path, bytes.NewBuffer(data), &project)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// labelsPerPage is the page size used when listing labels.
+const labelsPerPage = 50
+
+// GetLabels returns all labels the user has access to.
+func (c *Client) GetLabels() (labels []*models.Label, err error) {
+	for page := 1; ; page++ {
+		var batch []*models.Label
+		path := fmt.Sprintf("labels?page=%d&per_page=%d", page, labelsPerPage)
+		err = c.get(path, &batch)
+		if err != nil {
+			return nil, err
+		}
+
+		labels = append(labels, batch...)
+		if len(batch) < labelsPerPage {
+			return labels, nil
+		}
+	}
+}
+
+// bucketsPath returns the path of the buckets of a project view. Instances
+// without views keep the buckets on the project.
+func (c *Client) bucketsPath(projectID int64, viewID int64) string {
+	if c.legacy() {
+		return fmt.Sprintf("projects/%d/buckets", projectID)
+	}
+
+	return fmt.Sprintf("projects/%d/views/%d/buckets", projectID, viewID)
+}
+
+// DeleteBucket deletes a bucket. Its tasks are moved to the default bucket.
+func (c *Client) DeleteBucket(bucket *models.Bucket) error {
+	path := fmt.Sprintf("%s/%d", c.bucketsPath(bucket.ProjectID, bucket.ProjectViewID), bucket.ID)
+	return c.del(path)
+}
+
 func (c *Client) CreateBucket(bucket *models.Bucket) error {
-	path := fmt.Sprintf("projects/%d/views/%d/buckets", bucket.ProjectID, bucket.ProjectViewID)
+	path := c.bucketsPath(bucket.ProjectID, bucket.ProjectViewID)
 	data, err := json.Marshal(bucket)
 	if err != nil {
 		return err
@@ -156,8 +328,43 @@ func (c *Client) CreateBucket(bucket *models.Bucket) error {
 	return nil
 }
 
+// tasksPerPage is the page size used when listing tasks, comments and
+// attachments.
+const tasksPerPage = 50
+
+// GetBucketTasks returns all tasks in the bucket bucketID of a kanban view.
+func (c *Client) GetBucketTasks(projectID int64, viewID int64, bucketID int64) (tasks []*models.Task, err error) {
+	query := "filter=" + url.QueryEscape(fmt.Sprintf("bucket_id = %d", bucketID))
+	tasksPath := fmt.Sprintf("projects/%d/views/%d/tasks", projectID, viewID)
+	if c.legacy() {
+		query = fmt.Sprintf("filter_by=bucket_id&filter_value=%d", bucketID)
+		tasksPath = c.bucketsPath(projectID, viewID)
+	}
+
+	for page := 1; ; page++ {
+		var buckets []*models.Bucket
+		path := fmt.Sprintf("%s?%s&page=%d&per_page=%d", tasksPath, query, page, tasksPerPage)
+		err = c.get(path, &buckets)
+		if err != nil {
+			return nil, err
+		}
+
+		var batch []*models.Task
+		for _, bucket := range buckets {
+			if bucket.ID == bucketID {
+				batch = bucket.Tasks
+			}
+		}
+
+		tasks = append(tasks, batch...)
+		if len(batch) < tasksPerPage {
+			return tasks, nil
+		}
+	}
+}
+
 func (c *Client) UpdateBucket(bucket *models.Bucket) error {
-	url := fmt.Sprintf("projects/%d/views/%d/buckets/%d", bucket.ProjectID, bucket.ProjectViewID, bucket.ID)
+	url := fmt.Sprintf("%s/%d", c.bucketsPath(bucket.ProjectID, bucket.ProjectViewID), bucket.ID)
 	data, err := json.Marshal(bucket)
 	if err != nil {
 		return err
@@ -170,6 +377,22 @@ func (c *Client) UpdateBucket(bucket *models.Bucket) error {
 	return nil
 }
 
+func (c *Client) GetTask(taskID int64) (task *models.Task, err error) {
+	path := fmt.Sprintf("tasks/%d", taskID)
+	err = c.get(path, &task)
+	if err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// DeleteTask deletes a task with its comments, attachments and relations.
+func (c *Client) DeleteTask(taskID int64) error {
+	path := fmt.Sprintf("tasks/%d", taskID)
+	return c.del(path)
+}
+
 func (c *Client) AddTask(task *models.Task) error {
 
 	url := fmt.Sprintf("projects/%d/tasks", task.ProjectID)
@@ -185,6 +408,62 @@ func (c *Client) AddTask(task *models.Task) error {
 	return nil
 }
 
+func (c *Client) UpdateTask(task *models.Task) error {
+
+	url := fmt.Sprintf("tasks/%d", task.ID)
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	err = c.post(url, bytes.NewBuffer(data), &task)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateTaskPosition sets the position of a task in a view. Instances
+// without views have a single position per task, which is updated with the
+// task itself.
+func (c *Client) UpdateTaskPosition(position *models.TaskPosition) error {
+	if c.legacy() {
+		task, err := c.GetTask(position.TaskID)
+		if err != nil {
+			return err
+		}
+		task.Position = position.Position
+		return c.UpdateTask(task)
+	}
+
+	url := fmt.Sprintf("tasks/%d/position", position.TaskID)
+	data, err := json.Marshal(position)
+	if err != nil {
+		return err
+	}
+	err = c.post(url, bytes.NewBuffer(data), &position)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) CreateTaskRelation(relation *models.TaskRelation) error {
+
+	url := fmt.Sprintf("tasks/%d/relations", relation.TaskID)
+	data, err := json.Marshal(relation)
+	if err != nil {
+		return err
+	}
+	err = c.put(url, bytes.NewBuffer(data), &relation)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (c *Client) AddTaskComment(comment *models.TaskComment) error {
 
 	url := fmt.Sprintf("tasks/%d/comments", comment.TaskID)
@@ -200,7 +479,71 @@ func (c *Client) AddTaskComment(comment *models.TaskComment) error {
 	return nil
 }
 
+func (c *Client) UpdateTaskComment(comment *models.TaskComment) error {
+
+	url := fmt.Sprintf("tasks/%d/comments/%d", comment.TaskID, comment.ID)
+	data, err := json.Marshal(comment)
+	if err != nil {
+		return err
+	}
+	err = c.post(url, bytes.NewBuffer(data), &comment)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetTaskComments returns all comments of a task.
+func (c *Client) GetTaskComments(taskID int64) (comments []*models.TaskComment, err error) {
+	for page := 1; ; page++ {
+		var batch []*models.TaskComment
+		path := fmt.Sprintf("tasks/%d/comments?page=%d&per_page=%d", taskID, page, tasksPerPage)
+		err = c.get(path, &batch)
+		if err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, batch...)
+		if len(batch) < tasksPerPage {
+			return comments, nil
+		}
+	}
+}
+
+// GetTaskAttachments returns the attachments of a task, without their
+// content. Use DownloadTaskAttachment to get that.
+func (c *Client) GetTaskAttachments(taskID int64) (attachments []*models.TaskAttachment, err error) {
+	for page := 1; ; page++ {
+		var batch []*models.TaskAttachment
+		path := fmt.Sprintf("tasks/%d/attachments?page=%d&per_page=%d", taskID, page, tasksPerPage)
+		err = c.get(path, &batch)
+		if err != nil {
+			return nil, err
+		}
+
+		attachments = append(attachments, batch...)
+		if len(batch) < tasksPerPage {
+			return attachments, nil
+		}
+	}
+}
+
+// DownloadTaskAttachment returns the content of an attachment.
+func (c *Client) DownloadTaskAttachment(taskID int64, attachmentID int64) ([]byte, error) {
+	path := fmt.Sprintf("tasks/%d/attachments/%d", taskID, attachmentID)
+	return c.getRaw(path)
+}
+
+// AddTaskAttachments uploads an attachment to a task. Files larger than the
+// instance accepts are refused before uploading, when the client detected it.
 func (c *Client) AddTaskAttachments(taskID int64, attachment *models.TaskAttachment) error {
+	if c.Info != nil {
+		limit, err := c.Info.MaxFileSizeBytes()
+		if err == nil && limit > 0 && int64(len(attachment.File.FileContent)) > limit {
+			return fmt.Errorf("attachment %s has %d bytes, more than the %s the Vikunja instance accepts", attachment.File.Name, len(attachment.File.FileContent), c.Info.MaxFileSize)
+		}
+	}
 
 	path := fmt.Sprintf("tasks/%d/attachments", taskID)
 
@@ -209,7 +552,6 @@ func (c *Client) AddTaskAttachments(taskID int64, attachment *models.TaskAttachm
 	writer := multipart.NewWriter(&requestBody)
 
 	part, err := writer.CreateFormFile("files", attachment.File.Name)
-	c.log("[vikunja] AddTaskAttachments %s", attachment.File.Name)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,86 @@
+package vikunja
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"wingaru.me/trello-migrate/pkg/models"
+)
+
+func TestBucketEndpoints(t *testing.T) {
+	tests := []struct {
+		version string
+		want    []string
+	}{
+		{
+			"v0.24.0",
+			[]string{
+				"PUT /projects/1/views/4/buckets",
+				"GET /projects/1/views/4/tasks?filter=bucket_id+%3D+2&page=1&per_page=50",
+				"POST /tasks/3/position",
+				"DELETE /projects/1/views/4/buckets/2",
+			},
+		},
+		{
+			"v0.23.1",
+			[]string{
+				"PUT /projects/1/buckets",
+				"GET /projects/1/buckets?filter_by=bucket_id&filter_value=2&page=1&per_page=50",
+				"GET /tasks/3",
+				"POST /tasks/3",
+				"DELETE /projects/1/buckets/2",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.version, func(t *testing.T) {
+			var requests []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				request := r.Method + " " + r.URL.Path
+				if r.URL.RawQuery != "" {
+					request += "?" + r.URL.RawQuery
+				}
+				requests = append(requests, request)
+
+				switch {
+				case r.URL.Path == "/info":
+					w.Write([]byte(`{"version":"` + test.version + `"}`))
+				case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/tasks/"):
+					w.Write([]byte(`{"id":3,"position":1}`))
+				case r.Method == http.MethodGet:
+					w.Write([]byte(`[]`))
+				default:
+					w.Write([]byte(`{"id":2}`))
+				}
+			}))
+			defer server.Close()
+
+			client := NewClient("token", server.URL)
+			if _, err := client.Detect(); err != nil {
+				t.Fatal(err)
+			}
+			requests = nil
+
+			bucket := &models.Bucket{ProjectID: 1, ProjectViewID: 4, Title: "Done"}
+			if err := client.CreateBucket(bucket); err != nil {
+				t.Fatalf("CreateBucket: %v", err)
+			}
+			if _, err := client.GetBucketTasks(1, 4, bucket.ID); err != nil {
+				t.Fatalf("GetBucketTasks: %v", err)
+			}
+			if err := client.UpdateTaskPosition(&models.TaskPosition{TaskID: 3, ProjectViewID: 4, Position: 65536}); err != nil {
+				t.Fatalf("UpdateTaskPosition: %v", err)
+			}
+			if err := client.DeleteBucket(bucket); err != nil {
+				t.Fatalf("DeleteBucket: %v", err)
+			}
+
+			if !reflect.DeepEqual(requests, test.want) {
+				t.Errorf("the requests are\n%s\nwant\n%s", strings.Join(requests, "\n"), strings.Join(test.want, "\n"))
+			}
+		})
+	}
+}
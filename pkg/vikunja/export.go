@@ -0,0 +1,174 @@
+package vikunja
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"wingaru.me/trello-migrate/pkg/models"
+)
+
+// MinExportVersion is the first Vikunja version with projects. Exports
+// before ProjectViewsVersion have no views, their buckets are on the project.
+const MinExportVersion = ProjectsVersion
+
+// ReadExport reads the projects of a Vikunja export. filename is either the
+// zip Vikunja sends for a data export, or the data.json extracted from it.
+func ReadExport(filename string) ([]models.Project, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	version := ""
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		data, version, err = readExportZip(data)
+		if err != nil {
+			return nil, fmt.Errorf("reading Vikunja export %s: %w", filename, err)
+		}
+	}
+
+	projects, err := parseExportData(data, version)
+	if err != nil {
+		return nil, fmt.Errorf("reading Vikunja export %s: %w", filename, err)
+	}
+
+	return projects, nil
+}
+
+// readExportZip returns data.json and the version from an export zip.
+// data.json is looked up at the root first, then in any directory, for zips
+// which were extracted and packed again.
+func readExportZip(content []byte) (data []byte, version string, err error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var dataFile, versionFile *zip.File
+	for _, file := range archive.File {
+		switch path.Base(file.Name) {
+		case "data.json":
+			if dataFile == nil || file.Name == "data.json" {
+				dataFile = file
+			}
+		case "VERSION":
+			if versionFile == nil || file.Name == "VERSION" {
+				versionFile = file
+			}
+		}
+	}
+	if dataFile == nil {
+		return nil, "", fmt.Errorf("no data.json in the zip, is it a Vikunja data export?")
+	}
+
+	data, err = readZipFile(dataFile)
+	if err != nil {
+		return nil, "", err
+	}
+	if versionFile != nil {
+		raw, err := readZipFile(versionFile)
+		if err != nil {
+			return nil, "", err
+		}
+		version = strings.TrimSpace(string(raw))
+	}
+
+	return data, version, nil
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	r, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// parseExportData checks that data is a list of projects and decodes it.
+// version is empty when unknown.
+func parseExportData(data []byte, version string) ([]models.Project, error) {
+	if version != "" && compareVersions(version, MinExportVersion) < 0 {
+		return nil, fmt.Errorf("exported from Vikunja %s, which has lists instead of projects; upgrade to %s or later and export again", version, MinExportVersion)
+	}
+
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return nil, fmt.Errorf("data.json is not a list of projects")
+	}
+
+	var raw []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("data.json is not a list of projects: %w", err)
+	}
+
+	for i, project := range raw {
+		if _, exists := project["id"]; !exists {
+			return nil, fmt.Errorf("project %d in data.json has no id", i+1)
+		}
+		if _, exists := project["title"]; !exists {
+			return nil, fmt.Errorf("project %d in data.json has no title", i+1)
+		}
+	}
+
+	var projects []models.Project
+	if err := json.Unmarshal(data, &projects); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// compareVersions compares two versions like v0.24.0, ignoring anything
+// after the patch number. Versions which aren't numbered, like unstable
+// builds, are newer than any numbered version.
+func compareVersions(a string, b string) int {
+	parse := func(version string) ([3]int, bool) {
+		var numbers [3]int
+		version = strings.TrimPrefix(version, "v")
+		if i := strings.IndexAny(version, "-+"); i >= 0 {
+			version = version[:i]
+		}
+		parts := strings.Split(version, ".")
+		if len(parts) != 3 {
+			return numbers, false
+		}
+		for i, part := range parts {
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return numbers, false
+			}
+			numbers[i] = n
+		}
+		return numbers, true
+	}
+
+	av, aok := parse(a)
+	bv, bok := parse(b)
+	switch {
+	case !aok && !bok:
+		return 0
+	case !aok:
+		return 1
+	case !bok:
+		return -1
+	}
+
+	for i := range av {
+		if av[i] != bv[i] {
+			if av[i] < bv[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,55 @@
+package vikunja
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v0.24.0", "v0.24.0", 0},
+		{"0.24.0", "v0.24.0", 0},
+		{"v0.24.1", "v0.24.0", 1},
+		{"v0.23.9", "v0.24.0", -1},
+		{"v0.24.0", "v0.100.0", -1},
+		{"v1.0.0", "v0.24.0", 1},
+		{"v0.24.0-rc1", "v0.24.0", 0},
+		{"v0.24.0+abc123", "v0.24.0", 0},
+		{"v0.23.0-rc1", "v0.24.0", -1},
+		{"unstable", "v0.24.0", 1},
+		{"v0.24.0", "main-1234", -1},
+		{"unstable", "dev", 0},
+	}
+	for _, test := range tests {
+		if got := compareVersions(test.a, test.b); got != test.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestParseExportData(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		version string
+		views   int
+		wantErr bool
+	}{
+		{"with views", `[{"id":1,"title":"Board","views":[{"id":1,"title":"List"},{"id":4,"title":"Kanban"}]}]`, "v0.24.1", 2, false},
+		{"before views", `[{"id":1,"title":"Board","buckets":[]}]`, "v0.23.1", 0, false},
+		{"unknown version", `[{"id":1,"title":"Board"}]`, "", 0, false},
+		{"lists", `[{"id":1,"title":"Board"}]`, "v0.20.4", 0, true},
+		{"no id", `[{"title":"Board"}]`, "v0.24.1", 0, true},
+		{"not a list", `{"id":1,"title":"Board"}`, "v0.24.1", 0, true},
+	}
+	for _, test := range tests {
+		projects, err := parseExportData([]byte(test.data), test.version)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: parseExportData returned error %v, want error %t", test.name, err, test.wantErr)
+			continue
+		}
+		if err == nil && len(projects[0].Views) != test.views {
+			t.Errorf("%s: the project has %d views, want %d", test.name, len(projects[0].Views), test.views)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package vikunja
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ProjectsVersion is the first Vikunja version with projects, which were
+// lists before. The client doesn't speak the endpoints of lists.
+const ProjectsVersion = "v0.21.0"
+
+// ProjectViewsVersion is the first Vikunja version with project views. The
+// client uses the older endpoints without views for instances before it.
+const ProjectViewsVersion = "v0.24.0"
+
+// Info is what an instance reports about itself at /info.
+type Info struct {
+	Version     string `json:"version"`
+	FrontendURL string `json:"frontend_url"`
+	// MaxFileSize is the largest attachment the instance accepts, like 20MB.
+	MaxFileSize            string   `json:"max_file_size"`
+	TaskAttachmentsEnabled bool     `json:"task_attachments_enabled"`
+	TaskCommentsEnabled    bool     `json:"task_comments_enabled"`
+	AvailableMigrators     []string `json:"available_migrators"`
+}
+
+// Feature is something a migration needs from the instance.
+type Feature string
+
+const (
+	FeatureProjects     Feature = "projects"
+	FeatureProjectViews Feature = "project views"
+	FeatureAttachments  Feature = "task attachments"
+	FeatureComments     Feature = "task comments"
+)
+
+// featureHints tell how to get a missing feature.
+var featureHints = map[Feature]string{
+	FeatureProjects:     "upgrade it to " + ProjectsVersion + " or later",
+	FeatureProjectViews: "upgrade it to " + ProjectViewsVersion + " or later",
+	FeatureAttachments:  "set service.enabletaskattachments in its config",
+	FeatureComments:     "set service.enabletaskcomments in its config",
+}
+
+// Supports reports whether the instance has feature.
+func (i *Info) Supports(feature Feature) bool {
+	switch feature {
+	case FeatureProjects:
+		return compareVersions(i.Version, ProjectsVersion) >= 0
+	case FeatureProjectViews:
+		return compareVersions(i.Version, ProjectViewsVersion) >= 0
+	case FeatureAttachments:
+		return i.TaskAttachmentsEnabled
+	case FeatureComments:
+		return i.TaskCommentsEnabled
+	}
+
+	return false
+}
+
+// MaxFileSizeBytes returns MaxFileSize in bytes, or 0 when the instance
+// didn't report it.
+func (i *Info) MaxFileSizeBytes() (int64, error) {
+	return parseFileSize(i.MaxFileSize)
+}
+
+// fileSizeUnits are the units of the file sizes in the config of Vikunja,
+// which counts in steps of 1024.
+var fileSizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+func parseFileSize(size string) (int64, error) {
+	size = strings.ToUpper(strings.TrimSpace(size))
+	if size == "" {
+		return 0, nil
+	}
+
+	multiplier := int64(1)
+	for _, unit := range fileSizeUnits {
+		if strings.HasSuffix(size, unit.suffix) {
+			size = strings.TrimSpace(strings.TrimSuffix(size, unit.suffix))
+			multiplier = unit.bytes
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(size, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid file size %q", size)
+	}
+
+	return int64(n * float64(multiplier)), nil
+}
+
+// Detect reads the version and limits of the instance and makes the client
+// use the endpoints it supports. Clients which didn't detect the instance
+// assume the latest api.
+func (c *Client) Detect() (*Info, error) {
+	var info *Info
+	err := c.get("info", &info)
+	if err != nil {
+		return nil, fmt.Errorf("reading the version of the Vikunja instance at %s, is it the api url? %w", c.BaseURL, err)
+	}
+	if info == nil || info.Version == "" {
+		return nil, fmt.Errorf("%s/info has no version, is it the api url of a Vikunja instance?", c.BaseURL)
+	}
+
+	c.Info = info
+	return info, nil
+}
+
+// Require returns an error naming the first of features the instance lacks.
+// It only checks instances the client detected.
+func (c *Client) Require(features ...Feature) error {
+	if c.Info == nil {
+		return nil
+	}
+
+	for _, feature := range features {
+		if !c.Info.Supports(feature) {
+			return fmt.Errorf("the Vikunja instance at %s (%s) doesn't support %s; %s", c.BaseURL, c.Info.Version, feature, featureHints[feature])
+		}
+	}
+
+	return nil
+}
+
+// legacy reports whether the instance predates project views.
+func (c *Client) legacy() bool {
+	return c.Info != nil && !c.Info.Supports(FeatureProjectViews)
+}
@@ -0,0 +1,33 @@
+package vikunja
+
+import "testing"
+
+func TestParseFileSize(t *testing.T) {
+	tests := []struct {
+		size    string
+		want    int64
+		wantErr bool
+	}{
+		{"20MB", 20 << 20, false},
+		{"20 mb", 20 << 20, false},
+		{"1.5GB", 3 << 29, false},
+		{"512KB", 512 << 10, false},
+		{"2TB", 2 << 40, false},
+		{"100B", 100, false},
+		{"1024", 1024, false},
+		{"", 0, false},
+		{"MB", 0, true},
+		{"twenty MB", 0, true},
+		{"-1MB", 0, true},
+	}
+	for _, test := range tests {
+		got, err := parseFileSize(test.size)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseFileSize(%q) returned error %v, want error %t", test.size, err, test.wantErr)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("parseFileSize(%q) = %d, want %d", test.size, got, test.want)
+		}
+	}
+}